@@ -1,3 +1,7 @@
 module handler
 
 go 1.24.5
+
+require golang.org/x/net v0.35.0
+
+require golang.org/x/text v0.22.0 // indirect
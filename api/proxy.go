@@ -2,16 +2,24 @@ package handler
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
 )
 
 // ProxyRequest defines the structure of the incoming JSON body
@@ -24,11 +32,21 @@ type ProxyRequest struct {
 
 // ProxyResponse defines the structure of the response we send back to the frontend
 type ProxyResponse struct {
-	Status  string        `json:"status"`
-	Headers []HeaderEntry `json:"headers"`
-	Body    string        `json:"body"`
-	Cookies []CookieInfo  `json:"cookies"`
-	Size    int64         `json:"size"`
+	Status     string        `json:"status"`
+	Headers    []HeaderEntry `json:"headers"`
+	Body       string        `json:"body"`
+	Cookies    []CookieInfo  `json:"cookies"`
+	Size       int64         `json:"size"`
+	Timing     *Timing       `json:"timing"`
+	RemoteAddr string        `json:"remoteAddr"`
+	Protocol   string        `json:"protocol"`
+	Redirects  []RedirectHop `json:"redirects"`
+	// Body encoding and media type, as in the desktop ResponseMsg
+	BodyEncoding string `json:"bodyEncoding"`
+	Binary       bool   `json:"binary"`
+	MediaType    string `json:"mediaType"`
+	Charset      string `json:"charset"`
+	HexPreview   string `json:"hexPreview"`
 }
 
 // HeaderEntry represents a single header key-value pair
@@ -49,6 +67,209 @@ type CookieInfo struct {
 	HttpOnly bool   `json:"httpOnly"`
 }
 
+// RedirectHop is one followed redirect, matching the desktop RedirectHop
+// shape (gostman-gui/client.go)
+type RedirectHop struct {
+	URL        string   `json:"url"`
+	Status     string   `json:"status"`
+	StatusCode int      `json:"statusCode"`
+	Location   string   `json:"location"`
+	SetCookies []string `json:"setCookies"`
+}
+
+// Timing is the per-phase breakdown in milliseconds, matching the desktop
+// Timing shape (gostman-gui/timing.go). Phases describe the final hop of a
+// redirect chain; Total covers the whole exchange.
+type Timing struct {
+	DNS              float64 `json:"dns"`
+	Connect          float64 `json:"connect"`
+	TLS              float64 `json:"tls"`
+	TTFB             float64 `json:"ttfb"`
+	Download         float64 `json:"download"`
+	Total            float64 `json:"total"`
+	ReusedConnection bool    `json:"reusedConnection"`
+}
+
+// requestTrace collects httptrace timestamps; callbacks may run on
+// different goroutines, so fields are guarded by mu.
+type requestTrace struct {
+	mu                        sync.Mutex
+	start, dnsStart, dnsDone  time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	remoteAddr                string
+	reused                    bool
+}
+
+func (t *requestTrace) set(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			t.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone:       func(string, string, error) { t.set(&t.connectDone) },
+		TLSHandshakeStart: func() { t.set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.set(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte) },
+	}
+}
+
+func (t *requestTrace) timing(end time.Time) *Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &Timing{
+		DNS:              phaseMs(t.dnsStart, t.dnsDone),
+		Connect:          phaseMs(t.connectStart, t.connectDone),
+		TLS:              phaseMs(t.tlsStart, t.tlsDone),
+		TTFB:             phaseMs(t.wroteRequest, t.firstByte),
+		Download:         phaseMs(t.firstByte, end),
+		Total:            phaseMs(t.start, end),
+		ReusedConnection: t.reused,
+	}
+}
+
+func phaseMs(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return float64(end.Sub(start).Microseconds()) / 1000
+}
+
+// Body encodings for ProxyResponse.BodyEncoding, matching the desktop
+// content handling (gostman-gui/content.go).
+const (
+	bodyEncodingText    = "text"
+	bodyEncodingBase64  = "base64"
+	bodyEncodingDataURL = "dataurl"
+)
+
+const hexPreviewBytes = 512
+
+var textMediaTypes = map[string]bool{
+	"application/json":                  true,
+	"application/xml":                   true,
+	"application/javascript":            true,
+	"application/ecmascript":            true,
+	"application/x-www-form-urlencoded": true,
+	"application/graphql":               true,
+	"application/yaml":                  true,
+	"application/x-yaml":                true,
+	"application/x-ndjson":              true,
+	"application/sql":                   true,
+}
+
+var binaryMediaTypes = map[string]bool{
+	"application/pdf":                 true,
+	"application/zip":                 true,
+	"application/gzip":                true,
+	"application/x-gzip":              true,
+	"application/x-tar":               true,
+	"application/x-7z-compressed":     true,
+	"application/protobuf":            true,
+	"application/x-protobuf":          true,
+	"application/vnd.google.protobuf": true,
+	"application/msgpack":             true,
+	"application/wasm":                true,
+}
+
+func isBinaryMediaType(mediaType string) bool {
+	for _, prefix := range []string{"image/", "audio/", "video/", "font/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return binaryMediaTypes[mediaType]
+}
+
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || textMediaTypes[mediaType] ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+func looksLikeText(body []byte) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+	for _, b := range body {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeContent fills the body fields of resp: text is converted to UTF-8
+// from its charset, binary is base64 with a hex dump preview, and images
+// become data URLs.
+func decodeContent(resp *ProxyResponse, contentType string, body []byte) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType, params, _ = mime.ParseMediaType(http.DetectContentType(body))
+		contentType = ""
+	}
+	resp.MediaType = mediaType
+
+	switch {
+	case isBinaryMediaType(mediaType):
+		resp.Binary = true
+	case isTextMediaType(mediaType):
+	default:
+		resp.Binary = !looksLikeText(body)
+	}
+
+	if !resp.Binary {
+		resp.BodyEncoding, resp.Charset = bodyEncodingText, "utf-8"
+		if contentType != "" && params["charset"] != "" || mediaType == "text/html" || !utf8.Valid(body) {
+			enc, name, _ := charset.DetermineEncoding(body, contentType)
+			resp.Charset = name
+			if name != "utf-8" {
+				if decoded, err := enc.NewDecoder().Bytes(body); err == nil {
+					body = decoded
+				}
+			}
+		}
+		resp.Body = string(body)
+		return
+	}
+
+	if strings.HasPrefix(mediaType, "image/") {
+		resp.BodyEncoding = bodyEncodingDataURL
+		resp.Body = "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(body)
+	} else {
+		resp.BodyEncoding = bodyEncodingBase64
+		resp.Body = base64.StdEncoding.EncodeToString(body)
+	}
+	resp.HexPreview = hex.Dump(body[:min(len(body), hexPreviewBytes)])
+}
+
 func isPrivateIP(ip net.IP) bool {
 	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
 }
@@ -137,6 +358,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			return (&net.Dialer{}).DialContext(ctx, network, addr)
 		},
 	}
+	var redirects []RedirectHop
 	client := &http.Client{
 		Timeout:   30 * time.Second,
 		Transport: transport,
@@ -147,6 +369,13 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			if isPrivateHost(req.Context(), req.URL.Hostname()) {
 				return http.ErrUseLastResponse
 			}
+			redirects = append(redirects, RedirectHop{
+				URL:        req.Response.Request.URL.String(),
+				Status:     req.Response.Status,
+				StatusCode: req.Response.StatusCode,
+				Location:   req.Response.Header.Get("Location"),
+				SetCookies: req.Response.Header.Values("Set-Cookie"),
+			})
 			return nil
 		},
 	}
@@ -164,6 +393,8 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Perform the request
+	trace := &requestTrace{start: time.Now()}
+	outReq = outReq.WithContext(httptrace.WithClientTrace(outReq.Context(), trace.clientTrace()))
 	resp, err := client.Do(outReq)
 	if err != nil {
 		writeProxyError(w, "Network Error", "Network Error: "+err.Error())
@@ -177,6 +408,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	// Read response body (limit to 50MB)
 	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 50*1024*1024))
+	end := time.Now()
 	if err != nil {
 		writeProxyError(w, "Read Error", "Failed to read response: "+err.Error())
 		return
@@ -208,24 +440,21 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// Convert images to base64 data URL
-	contentType := resp.Header.Get("Content-Type")
-	var responseBody string
-	if contentType != "" && strings.HasPrefix(strings.ToLower(contentType), "image/") {
-		mimeType := strings.Split(contentType, ";")[0]
-		base64Data := base64.StdEncoding.EncodeToString(bodyBytes)
-		responseBody = "data:" + mimeType + ";base64," + base64Data
-	} else {
-		responseBody = string(bodyBytes)
-	}
+	trace.mu.Lock()
+	remoteAddr := trace.remoteAddr
+	trace.mu.Unlock()
 
 	response := ProxyResponse{
-		Status:  resp.Status,
-		Headers: respHeaders,
-		Body:    responseBody,
-		Cookies: respCookies,
-		Size:    int64(len(bodyBytes)),
+		Status:     resp.Status,
+		Headers:    respHeaders,
+		Cookies:    respCookies,
+		Size:       int64(len(bodyBytes)),
+		Timing:     trace.timing(end),
+		RemoteAddr: remoteAddr,
+		Protocol:   resp.Proto,
+		Redirects:  redirects,
 	}
+	decodeContent(&response, resp.Header.Get("Content-Type"), bodyBytes)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
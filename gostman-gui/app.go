@@ -3,7 +3,6 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +10,7 @@ import (
 	"io/fs"
 	"log"
 	"net/http"
-	"net/url"
+	"net/http/httptrace"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -21,11 +20,28 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
 	ctx context.Context
+
+	// environmentId, when set, overrides the persisted active environment
+	// for requests sent by this App (used by the CLI's -env flag).
+	environmentId string
+	// noHistory disables recording sent requests in the history.
+	noHistory bool
+	// openURL shows a URL to the user, e.g. for OAuth 2.0 authorization.
+	// It is nil when no browser is available.
+	openURL func(url string)
+	// runtimeCtx is the Wails runtime context for dialogs and events. It is
+	// nil outside the GUI, where ctx may still be set (the CLI sets it).
+	runtimeCtx context.Context
+
+	// inflight maps the ids of running requests to their cancel funcs.
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightRequest
 }
 
 // NewApp creates a new App application struct
@@ -37,33 +53,100 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.runtimeCtx = ctx
+	a.openURL = func(url string) {
+		wailsruntime.BrowserOpenURL(ctx, url)
+	}
+}
+
+// emit sends a runtime event to the frontend; outside the GUI it does
+// nothing.
+func (a *App) emit(event string, data ...any) {
+	if a.runtimeCtx != nil {
+		wailsruntime.EventsEmit(a.runtimeCtx, event, data...)
+	}
 }
 
 // Data Structures
 
 type SavedData struct {
-	Variables string    `json:"variables"`
-	Requests  []Request `json:"requests"`
+	Variables           string        `json:"variables"`
+	Requests            []Request     `json:"requests"`
+	Environments        []Environment `json:"environments"`
+	ActiveEnvironmentId string        `json:"activeEnvironmentId"`
+	Folders             []Folder      `json:"folders"`
+	Settings            *Settings     `json:"settings"`
+	// Client certificates and CA bundles; see certs.go
+	Certificates []ClientCertificate `json:"certificates"`
+	CABundles    []CABundle          `json:"caBundles"`
+	// Proxies are tried in order; see proxies.go
+	Proxies []ProxyConfig `json:"proxies"`
 }
 
 type Request struct {
-	Id          string `json:"id"`
-	Name        string `json:"name"`
-	URL         string `json:"url"`
-	Method      string `json:"method"`
-	Headers     string `json:"headers"`
-	Body        string `json:"body"`
-	QueryParams string `json:"queryParams"`
-	Response    string `json:"response"`
-	FolderId    string `json:"folderId"`
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Method      string    `json:"method"`
+	Headers     KeyValues `json:"headers" ts_type:"string"`
+	Body        string    `json:"body"`
+	QueryParams KeyValues `json:"queryParams" ts_type:"string"`
+	Response    string    `json:"response"`
+	FolderId    string    `json:"folderId"`
+	Order       int       `json:"order"`
+	Variables   string    `json:"variables"`
+	// PathParams fill the :name variables in the URL path
+	PathParams KeyValues `json:"pathParams" ts_type:"string"`
+	// GraphQLVariables is the JSON object of variables sent with a GRAPHQL
+	// request's query (Body)
+	GraphQLVariables string `json:"graphqlVariables"`
+	// PreRequestScript runs before variables are substituted and TestScript
+	// after the response is received (see scripts.go)
+	PreRequestScript string `json:"preRequestScript"`
+	TestScript       string `json:"testScript"`
+	// Settings override the global client settings for this request
+	Settings *RequestSettings `json:"settings"`
+	// Auth is applied after variable substitution; nil inherits the folder's
+	Auth *AuthConfig `json:"auth"`
+	// BodyMode selects how the body is built; Form holds the fields of the
+	// urlencoded and multipart modes and BodyFile the path sent by the file
+	// mode (see body.go)
+	BodyMode string      `json:"bodyMode"`
+	Form     []FormField `json:"form"`
+	BodyFile string      `json:"bodyFile"`
 }
 
 type ResponseMsg struct {
-	Body     string        `json:"body"`
-	Status   string        `json:"status"`
-	Headers  []HeaderEntry `json:"headers"`
-	Cookies  []CookieInfo  `json:"cookies"`
-	Size     int64         `json:"size"`
+	Body    string        `json:"body"`
+	Status  string        `json:"status"`
+	Headers []HeaderEntry `json:"headers"`
+	Cookies []CookieInfo  `json:"cookies"`
+	// Size is the body size after decompression; CompressedSize is what
+	// was received, encoded with the ContentEncoding that was decoded
+	Size            int64         `json:"size"`
+	CompressedSize  int64         `json:"compressedSize"`
+	ContentEncoding string        `json:"contentEncoding"`
+	Timing          *Timing       `json:"timing"`
+	RemoteAddr      string        `json:"remoteAddr"`
+	Protocol        string        `json:"protocol"`
+	RequestId       string        `json:"requestId"`
+	Redirects       []RedirectHop `json:"redirects"`
+	// SavedPath is where DownloadRequest saved the body; Body then only
+	// holds a preview
+	SavedPath string `json:"savedPath"`
+	// BodyEncoding tells how Body holds the response body (see content.go);
+	// HexPreview dumps the start of binary bodies
+	BodyEncoding string `json:"bodyEncoding"`
+	Binary       bool   `json:"binary"`
+	MediaType    string `json:"mediaType"`
+	Charset      string `json:"charset"`
+	HexPreview   string `json:"hexPreview"`
+	// TestResults holds the pm.test outcomes of the test script; ScriptError
+	// is set if it threw outside a test. ScriptLogs is the console output
+	// of both scripts.
+	TestResults []TestResult `json:"testResults"`
+	ScriptError string       `json:"scriptError"`
+	ScriptLogs  []string     `json:"scriptLogs"`
 }
 
 type HeaderEntry struct {
@@ -99,32 +182,43 @@ func getAppDataPath() string {
 // getSavedData loads the entire data structure from disk.
 // It returns an empty SavedData if the file doesn't exist or errors.
 func getSavedData() SavedData {
+	data, err := loadSavedData()
+	if err != nil {
+		log.Println(err)
+	}
+	return data
+}
+
+// loadSavedData is like getSavedData but reports read and decode errors to
+// the caller. A missing file is not an error and yields an empty SavedData.
+func loadSavedData() (SavedData, error) {
 	dataMutex.RLock()
 	defer dataMutex.RUnlock()
 
+	return readDataFile()
+}
+
+// readDataFile reads and decodes jsonfilePath. Callers must hold dataMutex.
+func readDataFile() (SavedData, error) {
+	var data SavedData
 	file, err := os.ReadFile(jsonfilePath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return SavedData{}
+			return data, nil
 		}
-		log.Printf("Error reading data file: %v", err)
-		return SavedData{}
+		return data, fmt.Errorf("failed to read data file: %w", err)
 	}
-	var data SavedData
 	if len(file) > 0 {
-		err = json.Unmarshal(file, &data)
-		if err != nil {
-			log.Println("Error unmarshaling file data:", err)
+		if err := json.Unmarshal(file, &data); err != nil {
+			return data, fmt.Errorf("failed to unmarshal data: %w", err)
 		}
 	}
-	return data
+	return data, nil
 }
 
-// saveSavedData persists the data structure to disk.
-func saveSavedData(data SavedData) error {
-	dataMutex.Lock()
-	defer dataMutex.Unlock()
-
+// writeDataFile encodes data to jsonfilePath. The file is private to the
+// user as it holds credentials. Callers must hold dataMutex.
+func writeDataFile(data SavedData) error {
 	if err := os.MkdirAll(appFolder, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -134,12 +228,25 @@ func saveSavedData(data SavedData) error {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
 
-	if err := os.WriteFile(jsonfilePath, updatedData, 0644); err != nil {
+	if err := os.WriteFile(jsonfilePath, updatedData, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file, and older versions
+	// wrote it readable by everyone
+	if err := os.Chmod(jsonfilePath, 0600); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 	return nil
 }
 
+// saveSavedData persists the data structure to disk.
+func saveSavedData(data SavedData) error {
+	dataMutex.Lock()
+	defer dataMutex.Unlock()
+
+	return writeDataFile(data)
+}
+
 // mutateSavedData acquires a write lock, reads the file, calls fn to mutate
 // the data in-place, then writes the file back. It is safe against concurrent
 // goroutines because the lock is held for the entire read-modify-write cycle.
@@ -147,33 +254,14 @@ func (a *App) mutateSavedData(fn func(data *SavedData)) error {
 	dataMutex.Lock()
 	defer dataMutex.Unlock()
 
-	var data SavedData
-	file, err := os.ReadFile(jsonfilePath)
+	data, err := readDataFile()
 	if err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			return fmt.Errorf("failed to read data file: %w", err)
-		}
-	} else if len(file) > 0 {
-		if err := json.Unmarshal(file, &data); err != nil {
-			return fmt.Errorf("failed to unmarshal data: %w", err)
-		}
+		return err
 	}
 
 	fn(&data)
 
-	if err := os.MkdirAll(appFolder, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	updatedData, err := json.MarshalIndent(data, "", " ")
-	if err != nil {
-		return fmt.Errorf("failed to encode JSON: %w", err)
-	}
-
-	if err := os.WriteFile(jsonfilePath, updatedData, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	return nil
+	return writeDataFile(data)
 }
 
 var placeholderRe = regexp.MustCompile(`{{([^}]+)}}`)
@@ -208,7 +296,103 @@ func replacePlaceholders(input string, variables map[string]string) string {
 
 // --- Exported Methods (Callable from JS) ---
 
-func (a *App) SendRequest(method, urlStr, headersJSON, bodyStr, paramsJSON string) ResponseMsg {
+// SendRequest sends a request given as strings. headersJSON and paramsJSON
+// are JSON objects or arrays of KeyValue; for GRAPHQL requests paramsJSON
+// holds the query variables instead. requestId works with CancelRequest as
+// for ExecuteRequest.
+func (a *App) SendRequest(requestId, method, urlStr, headersJSON, bodyStr, paramsJSON string) ResponseMsg {
+	r := Request{Method: method, URL: urlStr, Body: bodyStr}
+	var err error
+	if r.Headers, err = parseKeyValues(headersJSON); err != nil {
+		return ResponseMsg{Body: "Error parsing Headers. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
+	}
+	if method == "GRAPHQL" {
+		r.GraphQLVariables = paramsJSON
+	} else if r.QueryParams, err = parseKeyValues(paramsJSON); err != nil {
+		return ResponseMsg{Body: "Error parsing Query Params. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
+	}
+	return a.ExecuteRequest(requestId, r)
+}
+
+// ExecuteRequest sends r, resolving placeholders from the request, its
+// folder, the active environment and the globals (see variableScopes).
+// Headers set on the request's folders are sent unless r overrides them.
+// Requests that reach the network are recorded in the history.
+//
+// requestId identifies the send for CancelRequest; pass "" to have one
+// generated. Either way it is echoed in ResponseMsg.RequestId.
+func (a *App) ExecuteRequest(requestId string, r Request) ResponseMsg {
+	return a.execute(requestId, r, nil)
+}
+
+// execute implements ExecuteRequest, streaming the response body to dl if
+// set (see DownloadRequest).
+func (a *App) execute(requestId string, r Request, dl *downloadTarget) ResponseMsg {
+	requestId, ctx, done := a.startRequest(requestId)
+	defer done()
+
+	var opts sendOptions
+	if dl != nil {
+		dl.requestId = requestId
+		opts.download = dl
+	}
+	resp, _ := a.sendRecorded(ctx, r, opts)
+	resp.RequestId = requestId
+	return resp
+}
+
+// sendRecorded sends r and records it in the history unless noHistory is
+// set. Like send, it also describes what was sent, if anything.
+func (a *App) sendRecorded(ctx context.Context, r Request, opts sendOptions) (ResponseMsg, *sentRequest) {
+	resp, sent := a.send(ctx, r, opts)
+	if sent != nil && !a.noHistory {
+		if err := recordHistory(r, sent, resp); err != nil {
+			log.Printf("Error recording history: %v", err)
+		}
+	}
+	return resp, sent
+}
+
+// sentRequest describes what send put on the wire, after variable
+// substitution and header merging, with its credentials redacted for the
+// history.
+type sentRequest struct {
+	method   string
+	url      string
+	headers  http.Header
+	body     string
+	duration time.Duration
+	// secrets names the credential headers and params of the send
+	secrets credentialNames
+}
+
+// sendOptions are the optional parts of a send.
+type sendOptions struct {
+	// download saves the response body to a file instead of returning it
+	download *downloadTarget
+	// locals are the pm.variables of the scripts; a collection run shares
+	// them between its requests. nil starts with none.
+	locals map[string]string
+	// row is the data file row of the current iteration of a collection
+	// run, if it has a data file
+	row map[string]string
+}
+
+// send builds and executes r. The returned sentRequest is nil when r failed
+// before a request could be built (e.g. a configuration error).
+func (a *App) send(ctx context.Context, r Request, opts sendOptions) (ResponseMsg, *sentRequest) {
+	dl := opts.download
+	method, urlStr, bodyStr := r.Method, r.URL, r.Body
+	if r.Headers.parseError() != nil {
+		return ResponseMsg{Body: "Error parsing Headers. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+	}
+	if r.QueryParams.parseError() != nil {
+		return ResponseMsg{Body: "Error parsing Query Params. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+	}
+	if r.PathParams.parseError() != nil {
+		return ResponseMsg{Body: "Error parsing Path Params. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+	}
+
 	// Handle GraphQL requests - convert to POST with JSON body
 	if method == "GRAPHQL" {
 		method = "POST"
@@ -221,7 +405,7 @@ func (a *App) SendRequest(method, urlStr, headersJSON, bodyStr, paramsJSON strin
 
 		// Parse variables if provided
 		var vars map[string]any
-		if err := json.Unmarshal([]byte(paramsJSON), &vars); err == nil {
+		if err := json.Unmarshal([]byte(r.GraphQLVariables), &vars); err == nil {
 			graphqlReq.Variables = vars
 		}
 
@@ -229,105 +413,209 @@ func (a *App) SendRequest(method, urlStr, headersJSON, bodyStr, paramsJSON strin
 		if err == nil {
 			bodyStr = string(formattedBody)
 		}
+	}
 
-		// Ensure Content-Type header is set (case-insensitive check)
-		var headers map[string]string
-		if err := json.Unmarshal([]byte(headersJSON), &headers); err == nil {
-			hasContentType := false
-			for k := range headers {
-				if strings.EqualFold(k, "Content-Type") {
-					hasContentType = true
-					break
-				}
-			}
-			if !hasContentType {
-				headers["Content-Type"] = "application/json"
-			}
-			updatedHeaders, err := json.Marshal(headers)
-			if err != nil {
-				return ResponseMsg{Body: fmt.Sprintf("Error encoding GraphQL headers: %v", err), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
+	// 1. Load and Merge Variable Scopes (coerce non-string values to string)
+	data := getSavedData()
+	scopes, err := a.variableScopes(data, r, opts.row)
+	if err != nil {
+		return ResponseMsg{Body: err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+	}
+	if opts.locals == nil {
+		opts.locals = map[string]string{}
+	}
+	scopes = append(variableScopes{{name: ScopeLocal, vars: opts.locals}}, scopes...)
+
+	// 1a. Run the pre-request script, which may set variables. Those set
+	// in the environment or globals are saved once the send is over.
+	scripts := newScriptEnv(scopes, a.environmentFor(&data) != nil)
+	defer scripts.persist(a)
+	if strings.TrimSpace(r.PreRequestScript) != "" {
+		if err := scripts.run(ctx, "pre-request.js", r.PreRequestScript, r, nil); err != nil {
+			if ctx.Err() == context.Canceled {
+				return cancelledResponse(), nil
 			}
-			headersJSON = string(updatedHeaders)
-		} else {
-			return ResponseMsg{Body: fmt.Sprintf("Error parsing GraphQL Headers JSON: %v", err), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
+			return ResponseMsg{Body: "Pre-request script error: " + scriptError(err), Status: "Error", Headers: nil, Cookies: nil, Size: 0, ScriptLogs: scripts.logs}, nil
 		}
 	}
+	variables := scopes.merged()
 
-	// 1. Load and Parse Variables (coerce non-string values to string)
-	variablesJSON := a.GetVariables()
-	var rawVars map[string]any
-	if err := json.Unmarshal([]byte(variablesJSON), &rawVars); err != nil {
-		return ResponseMsg{Body: "Error parsing Env Variables", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
+	// 1b. Inherit headers from the request's folders
+	inheritedHeaders := folderHeaders(data.folderChain(r.FolderId), variables)
+
+	// 1c. Apply the request's overrides to the global client settings
+	settings := data.settings().withOverrides(r.Settings)
+	if err := settings.validate(); err != nil {
+		return ResponseMsg{Body: "Invalid request settings: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
 	}
-	variables := coerceVariables(rawVars)
 
-	// 2. Variable Substitution
+	// 2. Variable Substitution; disabled header and param rows are dropped
 	urlStr = replacePlaceholders(urlStr, variables)
-	headersJSON = replacePlaceholders(headersJSON, variables)
-	paramsJSON = replacePlaceholders(paramsJSON, variables)
+	headers := r.Headers.resolved(variables)
+	params := r.QueryParams.resolved(variables)
 	bodyStr = replacePlaceholders(bodyStr, variables)
 
-	// 3. Parse Headers
-	var headers map[string]string
-	if err := json.Unmarshal([]byte(headersJSON), &headers); err != nil {
-		return ResponseMsg{Body: "Error parsing Headers. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
-	}
-
-	// 4. Parse Query Params & Build URL
-	if paramsJSON != "" {
-		var params map[string]string
-		if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
-			return ResponseMsg{Body: "Error parsing Query Params. Check JSON format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
-		}
-		parsedURL, err := url.Parse(urlStr)
-		if err != nil {
-			return ResponseMsg{Body: "Invalid URL format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}
-		}
-		q := parsedURL.Query()
-		for key, value := range params {
-			q.Set(key, value)
-		}
-		parsedURL.RawQuery = q.Encode()
-		urlStr = parsedURL.String()
+	// Ensure Content-Type header is set for GraphQL (case-insensitive check)
+	if r.Method == "GRAPHQL" && !headers.has("Content-Type") {
+		headers = append(headers, KeyValue{Key: "Content-Type", Value: "application/json", Enabled: true})
 	}
 
-	// 4b. Default scheme to https if missing
-	if !strings.Contains(urlStr, "://") {
-		urlStr = "https://" + urlStr
+	// 3. Build URL: path params, query params and the default https scheme
+	urlStr, err = buildURL(urlStr, params, r.PathParams.resolved(variables))
+	if err != nil {
+		return ResponseMsg{Body: "Invalid URL format.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
 	}
 
-	// 5. Build Request
+	// 4. Build Request
 	method = strings.ToUpper(strings.TrimSpace(method))
 	var req *http.Request
-	var err error
 
-	if len(bodyStr) > 10*1024*1024 {
-		return ResponseMsg{Body: "Request body too large (max 10MB)", Status: "Error", Headers: nil, Cookies: nil, Size: 0}
+	body := []byte(bodyStr)
+	bodyContentType, bodyFile := "", ""
+	var form *multipartBody
+	switch r.BodyMode {
+	case "", BodyRaw:
+		if len(bodyStr) > 10*1024*1024 {
+			return ResponseMsg{Body: "Request body too large (max 10MB)", Status: "Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
+	case BodyFile:
+		// Streamed from disk once the request is built, so the in-memory
+		// limit does not apply
+		bodyFile = replacePlaceholders(r.BodyFile, variables)
+		if bodyFile == "" {
+			return ResponseMsg{Body: "No body file selected.", Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
+		body, bodyStr = nil, "@"+bodyFile
+	case BodyMultipart:
+		// File parts are streamed from disk once the request is built;
+		// bodyStr keeps a printable form for the history
+		if form, bodyStr, err = newMultipartBody(r.Form, variables); err != nil {
+			return ResponseMsg{Body: "Invalid form body: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
+		body, bodyContentType = nil, form.contentType()
+	default:
+		// Form bodies are encoded from their fields; bodyStr keeps a
+		// printable form for the history
+		body, bodyContentType, bodyStr, err = encodeForm(r.BodyMode, r.Form, variables)
+		if err != nil {
+			return ResponseMsg{Body: "Invalid form body: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
 	}
 
 	// We only support a subset of methods with body for now, but standard http.NewRequest handles nil body fine for GET
-	reqBody := bytes.NewBuffer([]byte(bodyStr))
-	if bodyStr == "" {
-		req, err = http.NewRequest(method, urlStr, nil)
+	reqBody := bytes.NewBuffer(body)
+	if len(body) == 0 {
+		req, err = http.NewRequestWithContext(ctx, method, urlStr, nil)
 	} else {
-		req, err = http.NewRequest(method, urlStr, reqBody)
+		req, err = http.NewRequestWithContext(ctx, method, urlStr, reqBody)
 	}
 
 	if err != nil {
-		return ResponseMsg{Body: "Failed to create request: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}
+		return ResponseMsg{Body: "Failed to create request: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, nil
 	}
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	if bodyFile != "" {
+		bodyContentType, err = setFileBody(req, bodyFile)
+		if err != nil {
+			return ResponseMsg{Body: "Failed to open body file: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
+		// The transport closes the file once sent; this covers early returns
+		defer req.Body.Close()
+	}
+	if form != nil {
+		if err := form.setBody(req); err != nil {
+			return ResponseMsg{Body: "Invalid form body: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
+		defer req.Body.Close()
+	}
+	if !settings.DisableDecompression {
+		req.Header.Set("Accept-Encoding", defaultAcceptEncoding)
+	}
+	if r.BodyMode == BodyURLEncoded || r.BodyMode == BodyFile {
+		// Headers may still override the detected type
+		req.Header.Set("Content-Type", bodyContentType)
+	}
+	inheritedHeaders.overrideHeaders(headers).applyHeaders(req.Header)
+	if r.BodyMode == BodyMultipart {
+		// The boundary must match the encoded body
+		req.Header.Set("Content-Type", bodyContentType)
 	}
 
-	// 6. Execute (with timeout to prevent hanging UI)
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	// 4b. Prepare the client. Cookies and OAuth 2.0 tokens are kept per
+	// environment; cookies are stored back after the exchange.
+	envId := ""
+	if env := a.environmentFor(&data); env != nil {
+		envId = env.Id
+	}
+
+	var redirects []RedirectHop
+	streamed := dl != nil || bodyFile != "" || (form != nil && form.hasFiles())
+	client := newHTTPClient(settings, &data, envId, streamed, &redirects)
+	defer client.CloseIdleConnections()
+	jar, err := openCookieJar(envId)
+	if err != nil {
+		return ResponseMsg{Body: "Failed to load cookies: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+	}
+	client.Jar = jar
+	defer jar.persistLogged(envId)
+
+	// 4c. Apply auth (explicit or inherited from the folders)
+	var auth AuthConfig
+	if effective := effectiveAuth(&data, r); effective != nil {
+		auth = effective.substitute(variables)
+		if auth.Type == AuthOAuth2 {
+			token, err := a.oauth2Token(ctx, client, envId, auth)
+			if err != nil {
+				if ctx.Err() == context.Canceled {
+					return cancelledResponse(), nil
+				}
+				return ResponseMsg{Body: "OAuth 2.0 error: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, nil
+			}
+			req.Header.Set("Authorization", token.authorization())
+		} else if err := applyAuth(req, auth); err != nil {
+			return ResponseMsg{Body: "Invalid auth configuration: " + err.Error(), Status: "Configuration Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
 	}
+	// 4d. Ask where to save a download, now that the URL is resolved
+	if dl != nil && dl.path == "" {
+		if dl.path, err = dl.choose(defaultDownloadName(req.URL)); err != nil {
+			return ResponseMsg{Body: "Failed to choose a file: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, nil
+		}
+		if dl.path == "" {
+			return cancelledResponse(), nil
+		}
+	}
+	sent := &sentRequest{
+		method:  req.Method,
+		url:     req.URL.String(),
+		headers: req.Header.Clone(),
+		body:    bodyStr,
+	}
+	sent.redactCredentials(auth)
+
+	// 5. Execute
+	trace := newRequestTrace()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
 	resp, err := client.Do(req)
+	if err == nil && auth.Type == AuthDigest && resp.StatusCode == http.StatusUnauthorized {
+		// Answer the server's digest challenge and send again
+		retry, retryErr := digestRetry(req, resp, auth)
+		if retryErr != nil {
+			resp.Body.Close()
+			return ResponseMsg{Body: "Digest authentication failed: " + retryErr.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, sent
+		}
+		if retry != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			resp, err = client.Do(retry)
+		}
+	}
+	sent.duration = time.Since(trace.start)
 	if err != nil {
-		return ResponseMsg{Body: "Network Error: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}
+		if ctx.Err() == context.Canceled {
+			return cancelledResponse(), sent
+		}
+		return ResponseMsg{Body: "Network Error: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, sent
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -335,23 +623,42 @@ func (a *App) SendRequest(method, urlStr, headersJSON, bodyStr, paramsJSON strin
 		}
 	}()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// Decode the Content-Encoding whoever asked for it
+	respBody, decoded, err := decodeBody(resp.Body, resp.Header, !settings.DisableDecompression)
 	if err != nil {
-		return ResponseMsg{Body: "Failed to read response body: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}
-	}
-
-	// Check if response is an image and convert to base64 data URL
-	contentType := resp.Header.Get("Content-Type")
-	var responseBody string
-	if contentType != "" && strings.HasPrefix(strings.ToLower(contentType), "image/") {
-		// Encode image data as base64 and create data URL
-		mimeType := strings.Split(contentType, ";")[0]
-		base64Data := base64.StdEncoding.EncodeToString(bodyBytes)
-		responseBody = fmt.Sprintf("data:%s;base64,%s", mimeType, base64Data)
+		return ResponseMsg{Body: "Failed to decompress response body: " + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, sent
+	}
+	defer respBody.Close()
+
+	var bodyBytes []byte
+	var size int64
+	readErrPrefix := "Failed to read response body: "
+	if dl != nil {
+		// Only a preview of a downloaded body is kept in memory
+		total := resp.ContentLength
+		if len(decoded) > 0 {
+			total = -1
+		}
+		bodyBytes, size, err = dl.save(a, respBody, total)
+		readErrPrefix = "Failed to save response body: "
 	} else {
-		responseBody = string(bodyBytes)
+		bodyBytes, err = io.ReadAll(respBody)
+		size = int64(len(bodyBytes))
+	}
+	end := time.Now()
+	sent.duration = end.Sub(trace.start)
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return cancelledResponse(), sent
+		}
+		return ResponseMsg{Body: readErrPrefix + err.Error(), Status: "Error", Headers: nil, Cookies: nil, Size: 0}, sent
 	}
 
+	// Classify the body as text (converted to UTF-8) or binary (base64,
+	// images as data URLs). A downloaded image preview is partial, so it
+	// is not inlined; neither is a body left encoded.
+	content := decodeContent(resp.Header.Get("Content-Type"), bodyBytes, respBody.encoded, dl == nil)
+
 	// Collect response headers - each key-value pair as a separate entry
 	var respHeaders []HeaderEntry
 	for key, values := range resp.Header {
@@ -378,16 +685,58 @@ func (a *App) SendRequest(method, urlStr, headersJSON, bodyStr, paramsJSON strin
 		})
 	}
 
-	// Calculate response size
-	size := int64(len(bodyBytes))
-
-	return ResponseMsg{
-		Body:    responseBody,
-		Status:  resp.Status,
-		Headers: respHeaders,
-		Cookies: respCookies,
-		Size:    size,
+	savedPath := ""
+	if dl != nil {
+		savedPath = dl.path
+	}
+
+	msg := ResponseMsg{
+		Body:            content.body,
+		BodyEncoding:    content.encoding,
+		Binary:          content.binary,
+		MediaType:       content.mediaType,
+		Charset:         content.charset,
+		HexPreview:      content.hexPreview,
+		Status:          resp.Status,
+		Headers:         respHeaders,
+		Cookies:         respCookies,
+		Size:            size,
+		CompressedSize:  respBody.wire.n,
+		ContentEncoding: strings.Join(decoded, ", "),
+		Timing:          trace.timing(end),
+		RemoteAddr:      trace.remote(),
+		Protocol:        resp.Proto,
+		Redirects:       redirects,
+		SavedPath:       savedPath,
+	}
+
+	// 6. Run the test script against the response. A downloaded body is
+	// only seen up to the preview.
+	if strings.TrimSpace(r.TestScript) != "" {
+		text := content.body
+		if content.binary {
+			text = string(bodyBytes)
+		}
+		err := scripts.run(ctx, "test.js", r.TestScript, r, &scriptResponse{
+			code:    resp.StatusCode,
+			status:  resp.Status,
+			headers: respHeaders,
+			body:    text,
+			timeMs:  msg.Timing.Total,
+			size:    size,
+		})
+		if err != nil {
+			msg.ScriptError = scriptError(err)
+		}
+		msg.TestResults = scripts.tests
 	}
+	msg.ScriptLogs = scripts.logs
+	return msg, sent
+}
+
+// cancelledResponse is returned for requests aborted via CancelRequest.
+func cancelledResponse() ResponseMsg {
+	return ResponseMsg{Body: "Request cancelled", Status: "Cancelled", Headers: nil, Cookies: nil, Size: 0}
 }
 
 func (a *App) GetRequests() []Request {
@@ -447,29 +796,33 @@ func (a *App) DeleteRequest(id string) error {
 	return nil
 }
 
+// GetVariables returns the variables of the active environment, or the
+// top-level variables when no environment is active.
 func (a *App) GetVariables() string {
-	vars := getSavedData().Variables
+	data := getSavedData()
+	vars := data.Variables
+	if env := data.activeEnvironment(); env != nil {
+		vars = env.Variables
+	}
 	if vars == "" {
 		return "{}"
 	}
 	return vars
 }
 
+// SaveVariables replaces the variables of the active environment, or the
+// top-level variables when no environment is active.
 func (a *App) SaveVariables(variableString string) string {
-	// Validate JSON and coerce non-string values to strings (e.g. {"count": 5} -> {"count": "5"})
-	var rawVars map[string]any
-	if err := json.Unmarshal([]byte(variableString), &rawVars); err != nil {
-		return "Error: Invalid JSON structure"
-	}
-
-	coerced := coerceVariables(rawVars)
-	coercedJSON, err := json.Marshal(coerced)
+	coercedStr, err := normalizeVariables(variableString)
 	if err != nil {
-		return "Error: Failed to encode variables"
+		return "Error: " + err.Error()
 	}
 
-	coercedStr := string(coercedJSON)
 	if err := a.mutateSavedData(func(data *SavedData) {
+		if env := data.activeEnvironment(); env != nil {
+			env.Variables = coercedStr
+			return
+		}
 		data.Variables = coercedStr
 	}); err != nil {
 		return "Failed to save variables: " + err.Error()
@@ -477,6 +830,21 @@ func (a *App) SaveVariables(variableString string) string {
 	return "Environment Variables Saved Successfully"
 }
 
+// normalizeVariables validates a JSON object of variables and coerces
+// non-string values to strings (e.g. {"count": 5} -> {"count": "5"}).
+func normalizeVariables(variableString string) (string, error) {
+	var rawVars map[string]any
+	if err := json.Unmarshal([]byte(variableString), &rawVars); err != nil {
+		return "", errors.New("Invalid JSON structure")
+	}
+
+	coercedJSON, err := json.Marshal(coerceVariables(rawVars))
+	if err != nil {
+		return "", errors.New("Failed to encode variables")
+	}
+	return string(coercedJSON), nil
+}
+
 // ResetData clears all saved data (requests + variables) on disk so the app
 // returns to an empty state. Used by the desktop reset action.
 func (a *App) ResetData() error {
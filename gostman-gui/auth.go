@@ -0,0 +1,301 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Auth types for AuthConfig.Type.
+const (
+	AuthInherit = "inherit" // use the nearest folder's auth; also the zero value
+	AuthNone    = "none"
+	AuthBasic   = "basic"
+	AuthBearer  = "bearer"
+	AuthAPIKey  = "apikey"
+	AuthDigest  = "digest"
+	AuthOAuth2  = "oauth2"
+	AuthAWSV4   = "awsv4"
+)
+
+// AuthConfig describes how a request authenticates. Requests and folders
+// both carry one; a request without auth (or with Type "inherit") uses the
+// auth of its nearest folder that defines one. Every field may contain
+// {{placeholders}}, resolved like the rest of the request.
+type AuthConfig struct {
+	Type string `json:"type"`
+	// Basic and Digest
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Bearer
+	Token string `json:"token"`
+	// API key: sent as header Key: Value, or as query param Key=Value when
+	// In is "query"
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	In    string `json:"in"`
+	// OAuth 2.0; see oauth2.go
+	OAuth2 *OAuth2Config `json:"oauth2"`
+	// AWS Signature Version 4; see sigv4.go
+	AWS *AWSConfig `json:"aws"`
+}
+
+func (c *AuthConfig) inherits() bool {
+	return c == nil || c.Type == "" || c.Type == AuthInherit
+}
+
+// effectiveAuth returns the auth that applies to r, or nil for none.
+func effectiveAuth(data *SavedData, r Request) *AuthConfig {
+	auth := r.Auth
+	if auth.inherits() {
+		auth = nil
+		for _, folder := range data.folderChain(r.FolderId) {
+			if !folder.Auth.inherits() {
+				auth = folder.Auth
+				break
+			}
+		}
+	}
+	if auth == nil || auth.Type == AuthNone {
+		return nil
+	}
+	return auth
+}
+
+// substitute returns a copy of c with placeholders resolved.
+func (c AuthConfig) substitute(variables map[string]string) AuthConfig {
+	c = c.clone()
+	for _, field := range c.templateFields() {
+		*field = replacePlaceholders(*field, variables)
+	}
+	return c
+}
+
+// clone returns a copy of c that shares no OAuth 2.0 or AWS settings.
+func (c AuthConfig) clone() AuthConfig {
+	if c.OAuth2 != nil {
+		o := *c.OAuth2
+		c.OAuth2 = &o
+	}
+	if c.AWS != nil {
+		aws := *c.AWS
+		c.AWS = &aws
+	}
+	return c
+}
+
+// templateFields returns the fields of c that may hold placeholders,
+// including those of its OAuth 2.0 and AWS settings.
+func (c *AuthConfig) templateFields() []*string {
+	fields := []*string{&c.Username, &c.Password, &c.Token, &c.Key, &c.Value}
+	if o := c.OAuth2; o != nil {
+		fields = append(fields, &o.TokenURL, &o.AuthURL, &o.ClientId, &o.ClientSecret, &o.Scope)
+	}
+	if aws := c.AWS; aws != nil {
+		fields = append(fields, &aws.AccessKeyId, &aws.SecretAccessKey, &aws.SessionToken, &aws.Region, &aws.Service)
+	}
+	return fields
+}
+
+// applyAuth sets the credentials of c on req. Digest auth needs the
+// server's challenge first, so it is handled by digestRetry instead. It
+// must run after the URL and headers are final, as AWS signing covers them.
+func applyAuth(req *http.Request, c AuthConfig) error {
+	switch c.Type {
+	case AuthBasic:
+		req.SetBasicAuth(c.Username, c.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case AuthAPIKey:
+		if c.Key == "" {
+			return errors.New("API key name is required")
+		}
+		switch c.In {
+		case "", "header":
+			req.Header.Set(c.Key, c.Value)
+		case "query":
+			pair := url.QueryEscape(c.Key) + "=" + url.QueryEscape(c.Value)
+			if req.URL.RawQuery == "" {
+				req.URL.RawQuery = pair
+			} else {
+				req.URL.RawQuery += "&" + pair
+			}
+		default:
+			return fmt.Errorf("unknown API key location: %s", c.In)
+		}
+	case AuthDigest:
+		// Needs the server's challenge; see digestRetry
+	case AuthOAuth2:
+		// Needs a token first; see oauth2Token
+	case AuthAWSV4:
+		if c.AWS == nil {
+			return errors.New("AWS signature settings are missing")
+		}
+		return signV4(req, *c.AWS, time.Now())
+	default:
+		return fmt.Errorf("unknown auth type: %s", c.Type)
+	}
+	return nil
+}
+
+// digestRetry answers a 401 Digest challenge in resp with a copy of the
+// request that received it, carrying the Authorization header. That is
+// resp.Request, which differs from req when redirects were followed; the
+// copy keeps the context of req, as the client cancels that of
+// resp.Request once resp is closed. It returns nil if resp holds no
+// supported Digest challenge.
+func digestRetry(req *http.Request, resp *http.Response, c AuthConfig) (*http.Request, error) {
+	var challenge map[string]string
+	for _, h := range resp.Header.Values("WWW-Authenticate") {
+		if ch := parseDigestChallenge(h); ch != nil {
+			challenge = ch
+			break
+		}
+	}
+	if challenge == nil {
+		return nil, nil
+	}
+
+	challenged := resp.Request
+	retry := challenged.Clone(req.Context())
+	if challenged.Body != nil && challenged.Body != http.NoBody {
+		if challenged.GetBody == nil {
+			return nil, errors.New("request body cannot be replayed for digest auth")
+		}
+		body, err := challenged.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+
+	authorization, err := digestAuthorization(challenge, challenged.Method, challenged.URL.RequestURI(), c.Username, c.Password)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", authorization)
+	return retry, nil
+}
+
+// parseDigestChallenge parses a `Digest k=v, k="v"` challenge into its
+// parameters, or returns nil for other schemes.
+func parseDigestChallenge(header string) map[string]string {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil
+	}
+	params := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		var value string
+		if strings.HasPrefix(after, `"`) {
+			// A quoted-string; a backslash escapes the next character
+			var b strings.Builder
+			end := 1
+			for end < len(after) && after[end] != '"' {
+				if after[end] == '\\' && end+1 < len(after) {
+					end++
+				}
+				b.WriteByte(after[end])
+				end++
+			}
+			value = b.String()
+			rest = after[min(end+1, len(after)):]
+		} else {
+			value, rest, _ = strings.Cut(after, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// digestAuthorization computes the RFC 7616 Authorization header value for
+// a challenge. Only qop "auth" (or no qop) is supported.
+func digestAuthorization(ch map[string]string, method, uri, username, password string) (string, error) {
+	algorithm := ch["algorithm"]
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm: %s", algorithm)
+	}
+	h := func(s string) string {
+		sum := newHash()
+		io.WriteString(sum, s)
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+
+	qop := ""
+	if ch["qop"] != "" {
+		for _, q := range strings.Split(ch["qop"], ",") {
+			if strings.TrimSpace(q) == "auth" {
+				qop = "auth"
+			}
+		}
+		if qop == "" {
+			return "", fmt.Errorf("unsupported digest qop: %s", ch["qop"])
+		}
+	}
+
+	cnonceBytes := make([]byte, 16)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", err
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	const nc = "00000001"
+
+	realm, nonce := ch["realm"], ch["nonce"]
+	ha1 := h(username + ":" + realm + ":" + password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	var response string
+	if qop == "" {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	parts := []string{
+		"username=" + quoteParam(username),
+		"realm=" + quoteParam(realm),
+		"nonce=" + quoteParam(nonce),
+		"uri=" + quoteParam(uri),
+		"response=" + quoteParam(response),
+	}
+	if algorithm != "" {
+		parts = append(parts, "algorithm="+algorithm)
+	}
+	if opaque, ok := ch["opaque"]; ok {
+		parts = append(parts, "opaque="+quoteParam(opaque))
+	}
+	if qop != "" {
+		parts = append(parts, "qop="+qop, "nc="+nc, "cnonce="+quoteParam(cnonce))
+	}
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+// quoteParam writes s as an RFC 9110 quoted-string, escaping only `"` and
+// `\`; other characters, including non-ASCII ones, are sent as they are.
+func quoteParam(s string) string {
+	return `"` + quoteEscaper.Replace(s) + `"`
+}
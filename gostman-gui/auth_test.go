@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDigestAuthAfterRedirect(t *testing.T) {
+	useTempAppFolder(t)
+	const username, password, realm, nonce = `jörg "j" \ ü`, "pw", "test", "abc123"
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new?q=1", http.StatusFound)
+			return
+		}
+		params := parseDigestChallenge(r.Header.Get("Authorization"))
+		if params == nil {
+			w.Header().Set("WWW-Authenticate", `Digest realm="`+realm+`", nonce="`+nonce+`", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ha1 := md5hex(params["username"] + ":" + realm + ":" + password)
+		ha2 := md5hex(r.Method + ":" + params["uri"])
+		want := md5hex(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+		if params["username"] != username || params["uri"] != r.URL.RequestURI() || params["response"] != want {
+			t.Errorf("unexpected digest parameters: %q", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	resp, _ := NewApp().send(context.Background(), Request{
+		Method: "GET",
+		URL:    srv.URL + "/old",
+		Auth:   &AuthConfig{Type: AuthDigest, Username: username, Password: password},
+	}, sendOptions{})
+	if resp.Status != "200 OK" {
+		t.Fatalf("status %q: %s", resp.Status, resp.Body)
+	}
+}
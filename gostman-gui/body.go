@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Body modes for Request.BodyMode.
+const (
+	BodyRaw        = "raw" // Body is sent as-is; also the zero value
+	BodyURLEncoded = "urlencoded"
+	BodyMultipart  = "multipart"
+	BodyFile       = "file" // Request.BodyFile streamed from disk
+)
+
+// Form field types for FormField.Type.
+const (
+	FieldText = "text" // also the zero value
+	FieldFile = "file"
+)
+
+// FormField is one field of an urlencoded or multipart body. A multipart
+// field of Type "file" sends the local file at path Value.
+type FormField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+	// ContentType of a multipart part; for files it is detected when empty
+	ContentType string `json:"contentType"`
+	Enabled     bool   `json:"enabled"`
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// encodeForm encodes the enabled fields as an urlencoded body, with
+// placeholders resolved. It returns the body, its Content-Type and a
+// printable form of the body for the history, with credential fields
+// masked. Multipart bodies are built by newMultipartBody instead.
+func encodeForm(mode string, fields []FormField, variables map[string]string) ([]byte, string, string, error) {
+	if mode != BodyURLEncoded {
+		return nil, "", "", fmt.Errorf("unknown body mode: %s", mode)
+	}
+	var pairs, printable []string
+	for _, f := range fields {
+		if !f.Enabled {
+			continue
+		}
+		if f.Type == FieldFile {
+			return nil, "", "", fmt.Errorf("file field %q needs a multipart body", f.Key)
+		}
+		key, value := replacePlaceholders(f.Key, variables), replacePlaceholders(f.Value, variables)
+		pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		printable = append(printable, url.QueryEscape(key)+"="+url.QueryEscape(printableFieldValue(key, value)))
+	}
+	encoded := strings.Join(pairs, "&")
+	return []byte(encoded), "application/x-www-form-urlencoded", strings.Join(printable, "&"), nil
+}
+
+// multipartBody is a multipart form body whose file parts are streamed
+// from disk each time it is sent (see setBody).
+type multipartBody struct {
+	boundary string
+	parts    []multipartPart
+}
+
+// multipartPart is a resolved part: a text value, or the file at path.
+type multipartPart struct {
+	header textproto.MIMEHeader
+	value  string
+	path   string
+	size   int64
+}
+
+// newMultipartBody resolves the enabled fields into a multipart body. File
+// fields are checked and their Content-Type detected, but not read. It
+// also returns a printable form of the body for the history, where files
+// appear as key=@path and credential fields are masked.
+func newMultipartBody(fields []FormField, variables map[string]string) (*multipartBody, string, error) {
+	m := &multipartBody{boundary: multipart.NewWriter(io.Discard).Boundary()}
+	var summary []string
+	for _, f := range fields {
+		if !f.Enabled {
+			continue
+		}
+		key, value := replacePlaceholders(f.Key, variables), replacePlaceholders(f.Value, variables)
+		h := textproto.MIMEHeader{}
+		if f.Type != FieldFile {
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(key)))
+			if f.ContentType != "" {
+				h.Set("Content-Type", f.ContentType)
+			}
+			m.parts = append(m.parts, multipartPart{header: h, value: value})
+			summary = append(summary, key+"="+printableFieldValue(key, value))
+			continue
+		}
+
+		size, head, err := statFile(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file for field %q: %w", key, err)
+		}
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = detectContentType(value, head)
+		}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(key), quoteEscaper.Replace(filepath.Base(value))))
+		h.Set("Content-Type", contentType)
+		m.parts = append(m.parts, multipartPart{header: h, path: value, size: size})
+		summary = append(summary, key+"=@"+value)
+	}
+	return m, strings.Join(summary, "\n"), nil
+}
+
+// hasFiles reports whether the body streams any file parts.
+func (m *multipartBody) hasFiles() bool {
+	for _, p := range m.parts {
+		if p.path != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// contentType returns the Content-Type of the body, with its boundary.
+func (m *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// writeTo encodes the body to w. writeFile writes the contents of a file
+// part.
+func (m *multipartBody) writeTo(w io.Writer, writeFile func(w io.Writer, p multipartPart) error) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+	for _, p := range m.parts {
+		part, err := mw.CreatePart(p.header)
+		if err != nil {
+			return err
+		}
+		if p.path == "" {
+			_, err = io.WriteString(part, p.value)
+		} else {
+			err = writeFile(part, p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// setBody streams the body of req through a pipe, with its Content-Length
+// computed up front and GetBody encoding it again, reopening the files,
+// for redirects and auth retries. req.Body must be closed if req is not
+// sent, to stop the encoding goroutine.
+func (m *multipartBody) setBody(req *http.Request) error {
+	size := &countingWriter{}
+	if err := m.writeTo(size, func(_ io.Writer, p multipartPart) error {
+		size.n += p.size
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	req.ContentLength = size.n
+	req.GetBody = func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(m.writeTo(pw, copyFilePart))
+		}()
+		return pr, nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// copyFilePart copies the file of p to w, failing if the file changed size
+// since the body's Content-Length was computed.
+func copyFilePart(w io.Writer, p multipartPart) error {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	n, err := io.Copy(w, io.LimitReader(file, p.size+1))
+	if err == nil && n != p.size {
+		err = fmt.Errorf("%s changed while it was being sent", p.path)
+	}
+	return err
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// statFile returns the size and first bytes of the regular file at path.
+func statFile(path string) (int64, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	if info.IsDir() {
+		return 0, nil, fmt.Errorf("%s is a directory", path)
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, nil, err
+	}
+	return info.Size(), head[:n], nil
+}
+
+// setFileBody streams the file at path as the body of req, with its
+// Content-Length set and GetBody reopening the file for redirects and auth
+// retries. It returns the file's detected Content-Type.
+func setFileBody(req *http.Request, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+	if info.IsDir() {
+		file.Close()
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		file.Close()
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return "", err
+	}
+
+	req.ContentLength = info.Size()
+	if info.Size() == 0 {
+		file.Close()
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+	} else {
+		req.Body = file
+		req.GetBody = func() (io.ReadCloser, error) { return os.Open(path) }
+	}
+	return detectContentType(path, head[:n]), nil
+}
+
+// detectContentType guesses a file's media type from its extension, then
+// from its first bytes.
+func detectContentType(path string, head []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return http.DetectContentType(head)
+}
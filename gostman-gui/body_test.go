@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileBodyTimeoutSparesUpload(t *testing.T) {
+	useTempAppFolder(t)
+	// Large enough not to fit in the socket buffers, so the upload lasts
+	// as long as the server takes to read it
+	const size = 32 << 20
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n int64
+		for {
+			m, err := io.CopyN(io.Discard, r.Body, 1<<20)
+			n += m
+			if err != nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if n != size {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	timeout := 150
+	resp, _ := NewApp().send(context.Background(), Request{Method: "PUT", URL: srv.URL, BodyMode: BodyFile, BodyFile: path,
+		Settings: &RequestSettings{TimeoutMs: &timeout}}, sendOptions{})
+	if resp.Status != "200 OK" {
+		t.Errorf("status %q: %s", resp.Status, resp.Body)
+	}
+}
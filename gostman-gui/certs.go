@@ -0,0 +1,288 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ClientCertificate is a client certificate presented to hosts matching
+// Host during the TLS handshake. It is either a PEM certificate and key
+// pair (CertFile, KeyFile) or a PKCS#12 bundle (PfxFile, Passphrase). File
+// paths are relative to the app data folder; the files are copied there
+// when the certificate is added.
+type ClientCertificate struct {
+	Id string `json:"id"`
+	// Host is "example.com", "*.example.com" (subdomains only) or "*", with
+	// an optional ":port"
+	Host       string `json:"host"`
+	CertFile   string `json:"certFile"`
+	KeyFile    string `json:"keyFile"`
+	PfxFile    string `json:"pfxFile"`
+	Passphrase string `json:"passphrase"`
+}
+
+// CABundle is a PEM file of extra root certificates trusted in addition to
+// the system roots. File is relative to the app data folder.
+type CABundle struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+func certsDir(id string) string {
+	return filepath.Join(appFolder, "certs", id)
+}
+
+// importCertFile copies src into the certificate directory for id as name
+// and returns the stored path relative to the app data folder. Each role
+// has its own name, as a certificate and its key may share a base name.
+func importCertFile(id, src, name string) (string, error) {
+	contents, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.MkdirAll(certsDir(id), 0700); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	rel := filepath.Join("certs", id, name)
+	if err := os.WriteFile(filepath.Join(appFolder, rel), contents, 0600); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return rel, nil
+}
+
+// load reads the certificate and its private key from the app data folder.
+func (c ClientCertificate) load() (tls.Certificate, error) {
+	if c.PfxFile != "" {
+		pfx, err := os.ReadFile(filepath.Join(appFolder, c.PfxFile))
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read %s: %w", c.PfxFile, err)
+		}
+		key, leaf, chain, err := pkcs12.DecodeChain(pfx, c.Passphrase)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to decode %s: %w", c.PfxFile, err)
+		}
+		cert := tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: key, Leaf: leaf}
+		for _, ca := range chain {
+			cert.Certificate = append(cert.Certificate, ca.Raw)
+		}
+		return cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(appFolder, c.CertFile), filepath.Join(appFolder, c.KeyFile))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid certificate %s: %w", c.CertFile, err)
+	}
+	return cert, nil
+}
+
+// hostMatches reports whether pattern (see ClientCertificate.Host) covers
+// host, which may carry a port.
+func hostMatches(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	host = strings.ToLower(host)
+	if patternHost, patternPort, err := net.SplitHostPort(pattern); err == nil {
+		_, port, err := net.SplitHostPort(host)
+		if err != nil || port != patternPort {
+			return false
+		}
+		pattern = patternHost
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*."):
+		return strings.HasSuffix(host, pattern[1:])
+	default:
+		return host == pattern
+	}
+}
+
+// certificateFor returns the first client certificate registered for host
+// (host:port), or nil if none matches.
+func (data *SavedData) certificateFor(host string) *ClientCertificate {
+	for i := range data.Certificates {
+		if hostMatches(data.Certificates[i].Host, host) {
+			return &data.Certificates[i]
+		}
+	}
+	return nil
+}
+
+// brokenCABundles holds the ids of the CA bundles rootCAs failed to load,
+// so each is only logged once.
+var (
+	brokenCABundlesMu sync.Mutex
+	brokenCABundles   = map[string]bool{}
+)
+
+// rootCAs returns the system roots plus every CA bundle, or nil (the system
+// roots) if no bundles are registered. Bundles were checked when added; one
+// that can no longer be loaded is logged and skipped, so sends to hosts it
+// does not concern keep working.
+func (data *SavedData) rootCAs() *x509.CertPool {
+	if len(data.CABundles) == 0 {
+		return nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, b := range data.CABundles {
+		contents, err := os.ReadFile(filepath.Join(appFolder, b.File))
+		if err == nil && !pool.AppendCertsFromPEM(contents) {
+			err = errors.New("it contains no PEM certificates")
+		}
+		if err != nil {
+			brokenCABundlesMu.Lock()
+			if !brokenCABundles[b.Id] {
+				brokenCABundles[b.Id] = true
+				log.Printf("Skipping CA bundle %q: %v", b.Name, err)
+			}
+			brokenCABundlesMu.Unlock()
+		}
+	}
+	return pool
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetCertificates returns the registered client certificates.
+func (a *App) GetCertificates() []ClientCertificate {
+	data := getSavedData()
+	result := make([]ClientCertificate, len(data.Certificates))
+	copy(result, data.Certificates)
+	return result
+}
+
+// AddCertificate registers a client certificate. Its file fields are paths
+// to the files to import; they are checked and copied into the app data
+// folder, and the stored certificate is returned.
+func (a *App) AddCertificate(c ClientCertificate) (ClientCertificate, error) {
+	c.Host = strings.TrimSpace(c.Host)
+	if c.Host == "" {
+		return ClientCertificate{}, errors.New("host is required")
+	}
+	if c.PfxFile == "" && (c.CertFile == "" || c.KeyFile == "") {
+		return ClientCertificate{}, errors.New("a PKCS#12 file or a certificate and key file are required")
+	}
+	c.Id = uuid.New().String()
+
+	var err error
+	files := []struct {
+		path *string
+		name string
+	}{{&c.CertFile, "cert.pem"}, {&c.KeyFile, "key.pem"}, {&c.PfxFile, "bundle.p12"}}
+	for _, f := range files {
+		if *f.path == "" {
+			continue
+		}
+		if *f.path, err = importCertFile(c.Id, *f.path, f.name); err != nil {
+			os.RemoveAll(certsDir(c.Id))
+			return ClientCertificate{}, err
+		}
+	}
+	if _, err := c.load(); err != nil {
+		os.RemoveAll(certsDir(c.Id))
+		return ClientCertificate{}, err
+	}
+
+	if err := a.mutateSavedData(func(data *SavedData) {
+		data.Certificates = append(data.Certificates, c)
+	}); err != nil {
+		os.RemoveAll(certsDir(c.Id))
+		return ClientCertificate{}, err
+	}
+	return c, nil
+}
+
+// DeleteCertificate unregisters a client certificate and deletes its files.
+func (a *App) DeleteCertificate(id string) error {
+	var notFound bool
+	err := a.mutateSavedData(func(data *SavedData) {
+		for i, c := range data.Certificates {
+			if c.Id == id {
+				data.Certificates = append(data.Certificates[:i], data.Certificates[i+1:]...)
+				return
+			}
+		}
+		notFound = true
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("certificate not found: %s", id)
+	}
+	return os.RemoveAll(certsDir(id))
+}
+
+// GetCABundles returns the registered CA bundles.
+func (a *App) GetCABundles() []CABundle {
+	data := getSavedData()
+	result := make([]CABundle, len(data.CABundles))
+	copy(result, data.CABundles)
+	return result
+}
+
+// AddCABundle imports the PEM file at path as a trusted CA bundle.
+func (a *App) AddCABundle(name, path string) (CABundle, error) {
+	b := CABundle{Id: uuid.New().String(), Name: strings.TrimSpace(name)}
+	if b.Name == "" {
+		b.Name = filepath.Base(path)
+	}
+	file, err := importCertFile(b.Id, path, "ca.pem")
+	if err != nil {
+		return CABundle{}, err
+	}
+	b.File = file
+
+	contents, err := os.ReadFile(filepath.Join(appFolder, file))
+	if err == nil && !x509.NewCertPool().AppendCertsFromPEM(contents) {
+		err = fmt.Errorf("%s contains no PEM certificates", path)
+	}
+	if err == nil {
+		err = a.mutateSavedData(func(data *SavedData) {
+			data.CABundles = append(data.CABundles, b)
+		})
+	}
+	if err != nil {
+		os.RemoveAll(certsDir(b.Id))
+		return CABundle{}, err
+	}
+	return b, nil
+}
+
+// DeleteCABundle unregisters a CA bundle and deletes its file.
+func (a *App) DeleteCABundle(id string) error {
+	var notFound bool
+	err := a.mutateSavedData(func(data *SavedData) {
+		for i, b := range data.CABundles {
+			if b.Id == id {
+				data.CABundles = append(data.CABundles[:i], data.CABundles[i+1:]...)
+				return
+			}
+		}
+		notFound = true
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("CA bundle not found: %s", id)
+	}
+	return os.RemoveAll(certsDir(id))
+}
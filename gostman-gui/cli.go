@@ -0,0 +1,276 @@
+//go:build cli
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+)
+
+// This file provides the headless `gostman` command. It shares app.go with the
+// desktop build but replaces the Wails entrypoint in main.go, so it builds
+// without the frontend or any webview dependencies:
+//
+//	go build -tags cli -o gostman .
+
+const cliUsage = `Usage: gostman run [flags] [id-or-name ...]
+
+Runs saved requests from gostman.json and prints the responses. Requests are
+selected by id, name or folder; with no selectors every saved request runs.
+
+Flags:
+`
+
+// Exit codes
+const (
+	exitOK       = 0
+	exitFailed   = 1 // a request failed with a network or configuration error
+	exitUsage    = 2 // bad flags or unreadable data file
+	exitHTTPFail = 3 // -fail was given and a response had status >= 400
+	exitTestFail = 4 // a test script failed or threw
+)
+
+func main() {
+	os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// runFlags holds the parsed flags of `gostman run`.
+type runFlags struct {
+	dataFile       string
+	id             string
+	name           string
+	folder         string
+	env            string
+	includeHeaders bool
+	quiet          bool
+	failHTTP       bool
+	history        bool
+	iterations     int
+	delay          time.Duration
+	bail           bool
+	iterationData  string
+}
+
+func newRunFlags(stderr io.Writer) (*flag.FlagSet, *runFlags) {
+	opts := &runFlags{}
+	fs := flag.NewFlagSet("gostman run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, cliUsage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.dataFile, "data", jsonfilePath, "path to the gostman.json data file")
+	fs.StringVar(&opts.id, "id", "", "run the request with this id")
+	fs.StringVar(&opts.name, "name", "", "run requests with this name")
+	fs.StringVar(&opts.folder, "folder", "", "run requests whose folderId matches")
+	fs.StringVar(&opts.env, "env", "", "environment `name or id` to use instead of the active one")
+	fs.BoolVar(&opts.includeHeaders, "i", false, "include redirects and response headers in the output")
+	fs.BoolVar(&opts.quiet, "q", false, "print only the status line of each response")
+	fs.BoolVar(&opts.failHTTP, "fail", false, "exit non-zero when a response status is 400 or above")
+	fs.BoolVar(&opts.history, "history", false, "record the requests in the history next to the data file")
+	fs.IntVar(&opts.iterations, "n", 0, "run the selected requests this many times (default once, or once per data row)")
+	fs.DurationVar(&opts.delay, "delay", 0, "pause between two requests")
+	fs.BoolVar(&opts.bail, "bail", false, "stop the run at the first failed request or test")
+	fs.StringVar(&opts.iterationData, "iteration-data", "", "CSV or JSON `file` whose rows are the data variables of successive iterations")
+	return fs, opts
+}
+
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	fs, opts := newRunFlags(stderr)
+	if len(args) == 0 || args[0] != "run" {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+	if opts.iterations < 0 || opts.delay < 0 {
+		fmt.Fprintln(stderr, "gostman: -n and -delay must not be negative")
+		return exitUsage
+	}
+
+	jsonfilePath = opts.dataFile
+	appFolder = filepath.Dir(opts.dataFile)
+
+	data, err := loadSavedData()
+	if err != nil {
+		fmt.Fprintf(stderr, "gostman: %v\n", err)
+		return exitUsage
+	}
+
+	selected := selectRequests(&data, opts.id, opts.name, opts.folder, fs.Args())
+	if len(selected) == 0 {
+		fmt.Fprintln(stderr, "gostman: no saved requests match the given selectors")
+		return exitUsage
+	}
+
+	// Ctrl-C cancels the in-flight request and stops the run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	app := NewApp()
+	app.ctx = ctx
+	app.noHistory = !opts.history
+	app.openURL = func(url string) {
+		fmt.Fprintf(stderr, "Open this URL in a browser to authorize:\n  %s\n", url)
+	}
+	if opts.env != "" {
+		env := findEnvironment(data.Environments, opts.env)
+		if env == nil {
+			fmt.Fprintf(stderr, "gostman: environment not found: %s\n", opts.env)
+			return exitUsage
+		}
+		app.environmentId = env.Id
+	}
+
+	var rows []map[string]string
+	if opts.iterationData != "" {
+		if rows, err = loadDataFile(opts.iterationData); err != nil {
+			fmt.Fprintf(stderr, "gostman: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	// The selected requests run like a collection, sharing the variables
+	// their scripts set
+	run := RunOptions{
+		FolderId:      opts.folder,
+		Iterations:    opts.iterations,
+		DelayMs:       int(opts.delay / time.Millisecond),
+		StopOnFailure: opts.bail,
+		DataFile:      opts.iterationData,
+	}
+	multiple := run.Iterations > 1 || len(rows) > 1
+	code := exitOK
+	summary := app.runRequests(ctx, "", selected, run, rows, func(result RunResult, resp ResponseMsg) {
+		fmt.Fprintf(stdout, "==> %s %s", result.Method, result.URL)
+		if result.Name != "" {
+			fmt.Fprintf(stdout, " (%s)", result.Name)
+		}
+		if multiple {
+			fmt.Fprintf(stdout, " [iteration %d]", result.Iteration)
+		}
+		fmt.Fprintln(stdout)
+
+		if sendFailed(resp) {
+			fmt.Fprintf(stderr, "%s: %s\n", resp.Status, resp.Body)
+			code = exitFailed
+			return
+		}
+
+		fmt.Fprintf(stdout, "%s (%d bytes", resp.Status, resp.Size)
+		if resp.Timing != nil {
+			fmt.Fprintf(stdout, ", %.1f ms", resp.Timing.Total)
+		}
+		fmt.Fprintln(stdout, ")")
+		if opts.failHTTP && statusCodeOf(resp.Status) >= 400 && code == exitOK {
+			code = exitHTTPFail
+		}
+		for _, line := range resp.ScriptLogs {
+			fmt.Fprintf(stderr, "console: %s\n", line)
+		}
+		if !printTestResults(stdout, resp) && code == exitOK {
+			code = exitTestFail
+		}
+		if opts.quiet {
+			return
+		}
+		if opts.includeHeaders {
+			for _, hop := range resp.Redirects {
+				fmt.Fprintf(stdout, "  %s %s -> %s\n", hop.Status, hop.URL, hop.Location)
+			}
+			for _, h := range resp.Headers {
+				fmt.Fprintf(stdout, "%s: %s\n", h.Key, h.Value)
+			}
+			fmt.Fprintln(stdout)
+		}
+		if resp.Binary {
+			// Binary bodies are base64 in ResponseMsg; a dump reads better
+			fmt.Fprintf(stdout, "[binary %s body, %d bytes]\n%s", resp.MediaType, resp.Size, resp.HexPreview)
+			return
+		}
+		fmt.Fprintln(stdout, resp.Body)
+	})
+
+	if summary.Requests > 1 {
+		fmt.Fprintf(stdout, "\n%d requests: %d passed, %d failed; %d tests: %d passed, %d failed (%.1f ms, avg response %.1f ms)\n",
+			summary.Requests, summary.Passed, summary.Failed, summary.Tests, summary.TestsPassed, summary.TestsFailed,
+			summary.DurationMs, summary.AvgResponseMs)
+	}
+	for _, row := range summary.DataRows {
+		fmt.Fprintf(stdout, "  row %d: %d passed, %d failed; tests %d passed, %d failed\n",
+			row.Iteration, row.Passed, row.Failed, row.TestsPassed, row.TestsFailed)
+	}
+	if summary.Stopped {
+		fmt.Fprintln(stderr, "gostman: run stopped after a failed request (-bail)")
+	}
+	return code
+}
+
+// selectRequests returns the saved requests matching any of the selectors, in
+// run order (see runOrder). A folder selector also matches requests in its
+// subfolders. With no selectors at all, every request is returned.
+func selectRequests(data *SavedData, id, name, folder string, idsOrNames []string) []Request {
+	if id == "" && name == "" && len(idsOrNames) == 0 {
+		return data.runOrder(folder)
+	}
+
+	var folders map[string]bool
+	if folder != "" {
+		folders = data.folderSubtree(folder)
+	}
+	var out []Request
+	for _, r := range data.runOrder("") {
+		match := (id != "" && r.Id == id) ||
+			(name != "" && r.Name == name) ||
+			(folder != "" && folders[r.FolderId])
+		for _, arg := range idsOrNames {
+			if r.Id == arg || r.Name == arg {
+				match = true
+			}
+		}
+		if match {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// findEnvironment looks up an environment by id, then by name.
+func findEnvironment(envs []Environment, idOrName string) *Environment {
+	for i := range envs {
+		if envs[i].Id == idOrName {
+			return &envs[i]
+		}
+	}
+	for i := range envs {
+		if envs[i].Name == idOrName {
+			return &envs[i]
+		}
+	}
+	return nil
+}
+
+// printTestResults prints the outcome of each test of resp's test script
+// and reports whether they all passed.
+func printTestResults(w io.Writer, resp ResponseMsg) bool {
+	passed := resp.ScriptError == ""
+	for _, t := range resp.TestResults {
+		if t.Passed {
+			fmt.Fprintf(w, "  PASS %s\n", t.Name)
+			continue
+		}
+		passed = false
+		fmt.Fprintf(w, "  FAIL %s: %s\n", t.Name, t.Error)
+	}
+	if resp.ScriptError != "" {
+		fmt.Fprintf(w, "  test script error: %s\n", resp.ScriptError)
+	}
+	return passed
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RedirectHop is one redirect response that was followed while sending a
+// request, in the order the hops happened.
+type RedirectHop struct {
+	URL        string   `json:"url"`
+	Status     string   `json:"status"`
+	StatusCode int      `json:"statusCode"`
+	Location   string   `json:"location"`
+	SetCookies []string `json:"setCookies"`
+}
+
+// newRedirectHop describes the redirect response that led to req.
+func newRedirectHop(req *http.Request) RedirectHop {
+	resp := req.Response
+	return RedirectHop{
+		URL:        resp.Request.URL.String(),
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Location:   resp.Header.Get("Location"),
+		SetCookies: resp.Header.Values("Set-Cookie"),
+	}
+}
+
+// hostTransport sends each request through a transport configured for its
+// host (host:port), so per-host TLS settings hold across redirects too.
+type hostTransport struct {
+	base      *http.Transport
+	configure func(t *http.Transport, host string) error
+	// requireHTTP2 fails requests that are not answered over HTTP/2
+	requireHTTP2 bool
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+// canonicalHost returns the host:port of u, adding the scheme's default
+// port if u has none.
+func canonicalHost(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func (h *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if h.requireHTTP2 && req.URL.Scheme != "https" {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("HTTP/2 requires an https URL: %s", req.URL.Redacted())
+	}
+	host := canonicalHost(req.URL)
+
+	h.mu.Lock()
+	t, ok := h.transports[host]
+	if !ok {
+		t = h.base.Clone()
+		if err := h.configure(t, host); err != nil {
+			h.mu.Unlock()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+		h.transports[host] = t
+	}
+	h.mu.Unlock()
+	resp, err := t.RoundTrip(req)
+	if err == nil && h.requireHTTP2 && resp.ProtoMajor != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s did not negotiate HTTP/2 (answered with %s)", host, resp.Proto)
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes the idle connections of every per-host
+// transport, so that http.Client.CloseIdleConnections releases them.
+func (h *hostTransport) CloseIdleConnections() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, t := range h.transports {
+		t.CloseIdleConnections()
+	}
+	h.base.CloseIdleConnections()
+}
+
+// newHTTPClient builds the client for one send from the effective settings
+// and the TLS material and proxy rules in data, using the proxies of
+// environment envId. A fresh transport is used per send so settings never
+// leak between requests; callers must call CloseIdleConnections when done
+// with the client, or its connections stay open. Every redirect the client
+// follows is appended to redirects. A streamed client, for bodies streamed
+// to or from disk, applies the timeout to connecting and waiting for the
+// response headers only, as such bodies may take longer to transfer.
+func newHTTPClient(s Settings, data *SavedData, envId string, streamed bool, redirects *[]RedirectHop) *http.Client {
+	timeout := time.Duration(s.TimeoutMs) * time.Millisecond
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc(data.proxyRules(envId))
+	// Bodies are decoded by send for every encoding, not just the gzip
+	// the transport would request and decode itself
+	transport.DisableCompression = true
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: s.InsecureSkipVerify,
+		RootCAs:            data.rootCAs(),
+	}
+	if s.HTTPVersion == HTTPVersionHTTP1 {
+		// A non-nil, empty TLSNextProto disables HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		transport.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	}
+	if s.HTTPVersion == HTTPVersionHTTP2 {
+		transport.ForceAttemptHTTP2 = true
+		transport.TLSClientConfig.NextProtos = []string{"h2"}
+	}
+
+	clientTimeout := timeout
+	if streamed && timeout > 0 {
+		clientTimeout = 0
+		transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+		transport.TLSHandshakeTimeout = min(transport.TLSHandshakeTimeout, timeout)
+		transport.ResponseHeaderTimeout = timeout
+	}
+
+	configure := func(t *http.Transport, host string) error {
+		if c := data.certificateFor(host); c != nil {
+			cert, err := c.load()
+			if err != nil {
+				return err
+			}
+			t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+		return nil
+	}
+
+	return &http.Client{
+		Timeout: clientTimeout,
+		Transport: &hostTransport{
+			base:         transport,
+			configure:    configure,
+			requireHTTP2: s.HTTPVersion == HTTPVersionHTTP2,
+			transports:   map[string]*http.Transport{},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// Returning the redirect response itself is more useful in an
+			// API client than Go's default "stopped after 10 redirects" error
+			if !s.FollowRedirects || len(via) > s.MaxRedirects {
+				return http.ErrUseLastResponse
+			}
+			*redirects = append(*redirects, newRedirectHop(req))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// defaultAcceptEncoding is sent unless the request sets Accept-Encoding
+// itself or decompression is disabled.
+const defaultAcceptEncoding = "gzip, deflate, br, zstd"
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// decodedBody reads a response body through the decoders for its
+// Content-Encoding. wire counts the encoded bytes received.
+type decodedBody struct {
+	io.Reader
+	wire *countingReader
+	// encoded is set when an encoding was left in place
+	encoded bool
+	closers []func()
+}
+
+func (d *decodedBody) Close() error {
+	for _, c := range d.closers {
+		c()
+	}
+	return nil
+}
+
+// decodeBody wraps body in a decoder for every Content-Encoding in header,
+// undoing the last applied encoding first. It stops at an encoding it does
+// not know, leaving that layer encoded; the encodings that were decoded are
+// returned in the order they were applied. If decode is false, or the body
+// is empty, the body is only counted.
+func decodeBody(body io.Reader, header http.Header, decode bool) (*decodedBody, []string, error) {
+	var encodings []string
+	for _, v := range header.Values("Content-Encoding") {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+				encodings = append(encodings, e)
+			}
+		}
+	}
+
+	d := &decodedBody{wire: &countingReader{r: body}}
+	d.Reader = d.wire
+	if !decode {
+		d.encoded = len(encodings) > 0
+		return d, nil, nil
+	}
+	// Responses without content (HEAD, 204, 304) may still name an
+	// encoding; the gzip and zlib readers would fail on the missing header
+	br := bufio.NewReader(d.wire)
+	if _, err := br.Peek(1); err == io.EOF {
+		return d, nil, nil
+	}
+	d.Reader = br
+	decoded := 0
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			var zr *gzip.Reader
+			if zr, err = gzip.NewReader(d.Reader); err == nil {
+				d.Reader, d.closers = zr, append(d.closers, func() { zr.Close() })
+			}
+		case "deflate":
+			d.Reader, err = newDeflateReader(d.Reader)
+		case "br":
+			d.Reader = brotli.NewReader(d.Reader)
+		case "zstd":
+			var zr *zstd.Decoder
+			if zr, err = zstd.NewReader(d.Reader, zstd.WithDecoderConcurrency(1)); err == nil {
+				d.Reader, d.closers = zr, append(d.closers, zr.Close)
+			}
+		default:
+			d.encoded = true
+			return d, encodings[i+1:], nil
+		}
+		if err != nil {
+			d.Close()
+			return nil, nil, fmt.Errorf("invalid %s body: %w", encodings[i], err)
+		}
+		decoded++
+	}
+	return d, encodings[len(encodings)-decoded:], nil
+}
+
+// newDeflateReader reads "deflate" content, which should be zlib-wrapped
+// but is sent as raw DEFLATE by some servers.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Body encodings for ResponseMsg.BodyEncoding.
+const (
+	BodyEncodingText    = "text"    // UTF-8 text, decoded from the response charset
+	BodyEncodingBase64  = "base64"  // raw bytes in standard base64
+	BodyEncodingDataURL = "dataurl" // an image as a data: URL for inline display
+)
+
+// hexPreviewBytes is how much of a binary body is shown in the hex dump.
+const hexPreviewBytes = 512
+
+// responseContent is a response body prepared for the frontend.
+type responseContent struct {
+	body       string
+	mediaType  string
+	charset    string
+	encoding   string
+	binary     bool
+	hexPreview string
+}
+
+// textMediaTypes are non-text/* media types whose bodies are text.
+var textMediaTypes = map[string]bool{
+	"application/json":                  true,
+	"application/xml":                   true,
+	"application/javascript":            true,
+	"application/ecmascript":            true,
+	"application/x-www-form-urlencoded": true,
+	"application/graphql":               true,
+	"application/yaml":                  true,
+	"application/x-yaml":                true,
+	"application/x-ndjson":              true,
+	"application/sql":                   true,
+}
+
+// binaryMediaTypes are always binary, even if they start like text.
+var binaryMediaTypes = map[string]bool{
+	"application/pdf":                 true,
+	"application/zip":                 true,
+	"application/gzip":                true,
+	"application/x-gzip":              true,
+	"application/x-tar":               true,
+	"application/x-7z-compressed":     true,
+	"application/protobuf":            true,
+	"application/x-protobuf":          true,
+	"application/vnd.google.protobuf": true,
+	"application/msgpack":             true,
+	"application/wasm":                true,
+}
+
+func isBinaryMediaType(mediaType string) bool {
+	for _, prefix := range []string{"image/", "audio/", "video/", "font/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return binaryMediaTypes[mediaType]
+}
+
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || textMediaTypes[mediaType] ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// looksLikeText reports whether body is UTF-8 without control characters
+// other than whitespace.
+func looksLikeText(body []byte) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+	for _, b := range body {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeContent classifies body by its Content-Type, sniffing it when the
+// header is missing or generic. A body still carrying a Content-Encoding
+// (encoded) is binary whatever its type. Text is converted to UTF-8 from its
+// charset; binary bodies are base64-encoded with a hex dump preview, except
+// images, which become data URLs when inlineImages is set.
+func decodeContent(contentType string, body []byte, encoded, inlineImages bool) responseContent {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType, params, _ = mime.ParseMediaType(http.DetectContentType(body))
+		contentType = ""
+	}
+	c := responseContent{mediaType: mediaType}
+
+	switch {
+	case encoded || isBinaryMediaType(mediaType):
+		c.binary = true
+	case isTextMediaType(mediaType):
+	default:
+		// application/octet-stream and unknown types are often text
+		c.binary = !looksLikeText(body)
+	}
+
+	if !c.binary {
+		c.encoding, c.charset = BodyEncodingText, "utf-8"
+		// Without a declared charset, UTF-8 is assumed unless the body is
+		// not valid UTF-8 or is HTML, which can declare it in a meta tag
+		if contentType != "" && params["charset"] != "" || mediaType == "text/html" || !utf8.Valid(body) {
+			enc, name, _ := charset.DetermineEncoding(body, contentType)
+			c.charset = name
+			if name != "utf-8" {
+				if decoded, err := enc.NewDecoder().Bytes(body); err == nil {
+					body = decoded
+				}
+			}
+		}
+		c.body = string(body)
+		return c
+	}
+
+	if inlineImages && !encoded && strings.HasPrefix(mediaType, "image/") {
+		c.encoding = BodyEncodingDataURL
+		c.body = "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(body)
+	} else {
+		c.encoding = BodyEncodingBase64
+		c.body = base64.StdEncoding.EncodeToString(body)
+	}
+	c.hexPreview = hex.Dump(body[:min(len(body), hexPreviewBytes)])
+	return c
+}
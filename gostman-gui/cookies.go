@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// JarCookie is a cookie stored in the persistent cookie jar. Domain never
+// has a leading dot; HostOnly cookies are sent to exactly that host, others
+// to its subdomains too. Expires is RFC 1123, or "" for a session cookie.
+type JarCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain"`
+	Path     string `json:"path"`
+	Expires  string `json:"expires"`
+	Secure   bool   `json:"secure"`
+	HttpOnly bool   `json:"httpOnly"`
+	HostOnly bool   `json:"hostOnly"`
+}
+
+// cookieStore maps an environment id ("" when no environment is active) to
+// the cookies collected while it was active.
+type cookieStore map[string][]JarCookie
+
+var cookiesMutex sync.Mutex
+
+func cookiesFilePath() string {
+	return filepath.Join(appFolder, "cookies.json")
+}
+
+// readCookies loads the cookie store. Callers must hold cookiesMutex.
+func readCookies() (cookieStore, error) {
+	store := cookieStore{}
+	file, err := os.ReadFile(cookiesFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read cookies file: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &store); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cookies: %w", err)
+		}
+	}
+	return store, nil
+}
+
+// mutateCookies runs fn over the cookies of one environment and writes the
+// store back, dropping expired cookies.
+func mutateCookies(envId string, fn func(cookies []JarCookie) []JarCookie) error {
+	cookiesMutex.Lock()
+	defer cookiesMutex.Unlock()
+
+	store, err := readCookies()
+	if err != nil {
+		return err
+	}
+	cookies := fn(store[envId])
+	now := time.Now()
+	kept := cookies[:0]
+	for _, c := range cookies {
+		if !c.expired(now) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		delete(store, envId)
+	} else {
+		store[envId] = kept
+	}
+	if len(store) == 0 {
+		if err := os.Remove(cookiesFilePath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove file: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(appFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	encoded, err := json.MarshalIndent(store, "", " ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if err := os.WriteFile(cookiesFilePath(), encoded, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func (c JarCookie) expired(now time.Time) bool {
+	if c.Expires == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC1123, c.Expires)
+	return err == nil && !t.After(now)
+}
+
+func (c JarCookie) sameCookie(o JarCookie) bool {
+	return c.Domain == o.Domain && c.Path == o.Path && c.Name == o.Name
+}
+
+// upsertCookie replaces the cookie with the same domain, path and name, or
+// appends c.
+func upsertCookie(cookies []JarCookie, c JarCookie) []JarCookie {
+	for i := range cookies {
+		if cookies[i].sameCookie(c) {
+			cookies[i] = c
+			return cookies
+		}
+	}
+	return append(cookies, c)
+}
+
+// httpCookie converts c for seeding a cookiejar.Jar, along with the URL it
+// must be set from.
+func (c JarCookie) httpCookie() (*url.URL, *http.Cookie) {
+	scheme := "http"
+	if c.Secure {
+		scheme = "https"
+	}
+	u := &url.URL{Scheme: scheme, Host: c.Domain, Path: c.Path}
+	hc := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+	if !c.HostOnly {
+		hc.Domain = c.Domain
+	}
+	if t, err := time.Parse(time.RFC1123, c.Expires); err == nil {
+		hc.Expires = t
+	}
+	return u, hc
+}
+
+// defaultCookiePath implements the RFC 6265 default-path algorithm.
+func defaultCookiePath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	if dir := path.Dir(p); dir != "." {
+		return dir
+	}
+	return "/"
+}
+
+// domainMatches reports whether host may set or receive cookies for domain.
+func domainMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// jarChange is one cookie set or removed by a response.
+type jarChange struct {
+	cookie JarCookie
+	remove bool
+}
+
+// recordingJar is the http.CookieJar used for one send. Cookie matching is
+// delegated to net/http/cookiejar; every cookie the server sets is also
+// recorded so it can be persisted after the exchange.
+type recordingJar struct {
+	jar *cookiejar.Jar
+
+	mu      sync.Mutex
+	changes []jarChange
+}
+
+// newRecordingJar seeds a jar with the stored cookies of an environment.
+func newRecordingJar(cookies []JarCookie) (*recordingJar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cookies {
+		u, hc := c.httpCookie()
+		jar.SetCookies(u, []*http.Cookie{hc})
+	}
+	return &recordingJar{jar: jar}, nil
+}
+
+func (j *recordingJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+func (j *recordingJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	host := strings.ToLower(u.Hostname())
+	for _, c := range cookies {
+		jc := JarCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.TrimPrefix(strings.ToLower(c.Domain), "."),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}
+		if jc.Domain == "" {
+			jc.Domain, jc.HostOnly = host, true
+		} else if !domainMatches(host, jc.Domain) {
+			// cookiejar rejects these too
+			continue
+		}
+		if jc.Path == "" || jc.Path[0] != '/' {
+			jc.Path = defaultCookiePath(u)
+		}
+
+		remove := c.MaxAge < 0
+		switch {
+		case c.MaxAge > 0:
+			jc.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second).UTC().Format(time.RFC1123)
+		case !c.Expires.IsZero():
+			jc.Expires = c.Expires.UTC().Format(time.RFC1123)
+			remove = remove || !c.Expires.After(time.Now())
+		}
+		j.changes = append(j.changes, jarChange{cookie: jc, remove: remove})
+	}
+}
+
+// persist merges the recorded changes into the environment's stored cookies.
+func (j *recordingJar) persist(envId string) error {
+	j.mu.Lock()
+	changes := j.changes
+	j.mu.Unlock()
+	if len(changes) == 0 {
+		return nil
+	}
+	return mutateCookies(envId, func(cookies []JarCookie) []JarCookie {
+		for _, ch := range changes {
+			if ch.remove {
+				cookies = removeCookies(cookies, ch.cookie.sameCookie)
+				continue
+			}
+			cookies = upsertCookie(cookies, ch.cookie)
+		}
+		return cookies
+	})
+}
+
+// persistLogged is persist for use in defer; errors are only logged.
+func (j *recordingJar) persistLogged(envId string) {
+	if err := j.persist(envId); err != nil {
+		log.Printf("Error saving cookies: %v", err)
+	}
+}
+
+func removeCookies(cookies []JarCookie, match func(JarCookie) bool) []JarCookie {
+	kept := cookies[:0]
+	for _, c := range cookies {
+		if !match(c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// openCookieJar returns the jar for a send resolved against envId.
+func openCookieJar(envId string) (*recordingJar, error) {
+	cookiesMutex.Lock()
+	store, err := readCookies()
+	cookiesMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return newRecordingJar(store[envId])
+}
+
+// cookieEnvironmentId is the cookie jar scope for sends from this App.
+func (a *App) cookieEnvironmentId() string {
+	data := getSavedData()
+	if env := a.environmentFor(&data); env != nil {
+		return env.Id
+	}
+	return ""
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetCookies returns the cookies of the active environment's jar for a
+// domain (including cookies set for its parent domains), or all cookies if
+// domain is "". Cookies are sorted by domain, path and name.
+func (a *App) GetCookies(domain string) ([]JarCookie, error) {
+	cookiesMutex.Lock()
+	store, err := readCookies()
+	cookiesMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	now := time.Now()
+	result := []JarCookie{}
+	for _, c := range store[a.cookieEnvironmentId()] {
+		if c.expired(now) {
+			continue
+		}
+		if domain == "" || domainMatches(domain, c.Domain) {
+			result = append(result, c)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Domain != result[j].Domain {
+			return result[i].Domain < result[j].Domain
+		}
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// SaveCookie adds a cookie to the active environment's jar, or replaces the
+// cookie with the same domain, path and name.
+func (a *App) SaveCookie(c JarCookie) error {
+	c.Domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(c.Domain)), ".")
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Domain == "" || c.Name == "" {
+		return errors.New("cookie name and domain are required")
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.Expires != "" {
+		if _, err := time.Parse(time.RFC1123, c.Expires); err != nil {
+			return fmt.Errorf("invalid expiry, expected RFC 1123: %s", c.Expires)
+		}
+	}
+	return mutateCookies(a.cookieEnvironmentId(), func(cookies []JarCookie) []JarCookie {
+		return upsertCookie(cookies, c)
+	})
+}
+
+func (a *App) DeleteCookie(domain, path, name string) error {
+	target := JarCookie{Domain: strings.TrimPrefix(strings.ToLower(domain), "."), Path: path, Name: name}
+	var notFound bool
+	err := mutateCookies(a.cookieEnvironmentId(), func(cookies []JarCookie) []JarCookie {
+		before := len(cookies)
+		cookies = removeCookies(cookies, target.sameCookie)
+		notFound = len(cookies) == before
+		return cookies
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("cookie not found: %s", name)
+	}
+	return nil
+}
+
+// ClearCookies removes the active environment's cookies for a domain and its
+// subdomains, or every cookie if domain is "".
+func (a *App) ClearCookies(domain string) error {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	return mutateCookies(a.cookieEnvironmentId(), func(cookies []JarCookie) []JarCookie {
+		if domain == "" {
+			return nil
+		}
+		return removeCookies(cookies, func(c JarCookie) bool {
+			return domainMatches(c.Domain, domain)
+		})
+	})
+}
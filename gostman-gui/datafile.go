@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadDataFile reads the rows of a runner data file: a CSV file whose first
+// record names the columns, or a JSON array of objects. Files ending in
+// .json are JSON, .csv are CSV, and others are sniffed. Values are coerced
+// to strings like variables.
+func loadDataFile(path string) ([]map[string]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+	contents = bytes.TrimPrefix(contents, []byte("\xef\xbb\xbf"))
+
+	var rows []map[string]string
+	trimmed := bytes.TrimSpace(contents)
+	looksJSON := len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".json", ext != ".csv" && looksJSON:
+		rows, err = parseJSONRows(contents)
+	default:
+		rows, err = parseCSVRows(contents)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid data file %s: %w", filepath.Base(path), err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("data file %s has no rows", filepath.Base(path))
+	}
+	return rows, nil
+}
+
+func parseJSONRows(contents []byte) ([]map[string]string, error) {
+	var raw []map[string]any
+	if err := json.Unmarshal(contents, &raw); err != nil {
+		return nil, errors.New("expected a JSON array of objects")
+	}
+	rows := make([]map[string]string, len(raw))
+	for i, r := range raw {
+		rows[i] = coerceVariables(r)
+	}
+	return rows, nil
+}
+
+func parseCSVRows(contents []byte) ([]map[string]string, error) {
+	r := csv.NewReader(bytes.NewReader(contents))
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, key := range header {
+			if key != "" {
+				row[key] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
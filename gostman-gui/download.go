@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// maxDownloadPreviewBytes is how much of a downloaded body is returned
+	// in ResponseMsg.Body.
+	maxDownloadPreviewBytes = 4 * 1024
+	// downloadProgressInterval throttles download progress events.
+	downloadProgressInterval = 100 * time.Millisecond
+	// DownloadProgressEvent is the runtime event carrying DownloadProgress.
+	DownloadProgressEvent = "download:progress"
+)
+
+// DownloadProgress reports a download in progress. Total is -1 when the
+// server did not send a Content-Length. The last event has Done set.
+type DownloadProgress struct {
+	RequestId string `json:"requestId"`
+	Path      string `json:"path"`
+	Received  int64  `json:"received"`
+	Total     int64  `json:"total"`
+	Done      bool   `json:"done"`
+}
+
+// downloadTarget makes send stream the response body to path instead of
+// returning it. When path is empty, send calls choose with a file name
+// suggested by the resolved URL once the request is built; choose returns
+// "" to cancel the send.
+type downloadTarget struct {
+	requestId string
+	path      string
+	choose    func(name string) (string, error)
+}
+
+// progressWriter counts the bytes written through it, keeps the first
+// maxDownloadPreviewBytes, and emits throttled progress events.
+type progressWriter struct {
+	a        *App
+	progress DownloadProgress
+	preview  []byte
+	lastEmit time.Time
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	if room := maxDownloadPreviewBytes - len(w.preview); room > 0 {
+		w.preview = append(w.preview, p[:min(room, len(p))]...)
+	}
+	w.progress.Received += int64(len(p))
+	if now := time.Now(); now.Sub(w.lastEmit) >= downloadProgressInterval {
+		w.lastEmit = now
+		w.a.emit(DownloadProgressEvent, w.progress)
+	}
+	return len(p), nil
+}
+
+// save streams body to the target through a temporary file that replaces
+// the target only once the whole body was written. It returns the preview
+// and the number of bytes written.
+func (d *downloadTarget) save(a *App, body io.Reader, total int64) ([]byte, int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), ".gostman-download-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := &progressWriter{a: a, progress: DownloadProgress{RequestId: d.requestId, Path: d.path, Total: total}}
+	_, err = io.Copy(io.MultiWriter(tmp, w), body)
+	// CreateTemp makes the file private; give it the mode of the file it
+	// replaces, or that of a normal save
+	mode := os.FileMode(0644)
+	if info, statErr := os.Stat(d.path); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	if chmodErr := tmp.Chmod(mode); err == nil {
+		err = chmodErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := os.Rename(tmp.Name(), d.path); err != nil {
+		return nil, 0, fmt.Errorf("failed to save file: %w", err)
+	}
+
+	w.progress.Done = true
+	a.emit(DownloadProgressEvent, w.progress)
+	return w.preview, w.progress.Received, nil
+}
+
+// defaultDownloadName suggests a file name from the last segment of a URL.
+func defaultDownloadName(u *url.URL) string {
+	if name := path.Base(u.Path); name != "/" && name != "." {
+		return name
+	}
+	return "response"
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// DownloadRequest sends r like ExecuteRequest but streams the response body
+// to a file chosen in a save dialog instead of holding it in memory. The
+// dialog opens once the URL is resolved, suggesting its last segment as the
+// file name. Progress is reported through "download:progress" events; the
+// response carries the first 4KB of the body and the path it was saved to.
+// Closing the dialog returns a cancelled response.
+func (a *App) DownloadRequest(requestId string, r Request) (ResponseMsg, error) {
+	if a.runtimeCtx == nil {
+		return ResponseMsg{}, errors.New("downloads need the desktop app")
+	}
+	return a.execute(requestId, r, &downloadTarget{choose: func(name string) (string, error) {
+		return wailsruntime.SaveFileDialog(a.runtimeCtx, wailsruntime.SaveDialogOptions{
+			Title:           "Save Response",
+			DefaultFilename: name,
+		})
+	}}), nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadSave(t *testing.T) {
+	dir := t.TempDir()
+	target := &downloadTarget{path: filepath.Join(dir, "response.bin")}
+	body := strings.Repeat("x", 5000)
+
+	preview, n, err := target.save(&App{}, strings.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(body)) || len(preview) != maxDownloadPreviewBytes {
+		t.Errorf("got %d bytes and a %d byte preview", n, len(preview))
+	}
+	contents, err := os.ReadFile(target.path)
+	if err != nil || string(contents) != body {
+		t.Fatalf("saved file does not hold the body: %v", err)
+	}
+	info, err := os.Stat(target.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0644 {
+		t.Errorf("new file mode = %v, want 0644", mode)
+	}
+
+	// Overwriting keeps the mode of the existing file
+	if err := os.Chmod(target.path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := target.save(&App{}, strings.NewReader("y"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = os.Stat(target.path); err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0640 {
+		t.Errorf("replaced file mode = %v, want 0640", mode)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestDownloadNameFromResolvedURL(t *testing.T) {
+	useTempAppFolder(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("report"))
+	}))
+	defer srv.Close()
+
+	a := NewApp()
+	if err := a.SaveGlobals(`{"base":"` + srv.URL + `","name":"report.csv"}`); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(t.TempDir(), "saved.csv")
+	var suggested string
+	dl := &downloadTarget{choose: func(name string) (string, error) {
+		suggested = name
+		return target, nil
+	}}
+	resp, _ := a.send(context.Background(), Request{Method: "GET", URL: "{{base}}/files/{{name}}"}, sendOptions{download: dl})
+	if resp.Status != "200 OK" || resp.SavedPath != target {
+		t.Fatalf("status %q, saved to %q: %s", resp.Status, resp.SavedPath, resp.Body)
+	}
+	if suggested != "report.csv" {
+		t.Errorf("suggested name = %q, want report.csv", suggested)
+	}
+}
+
+func TestDownloadTimeoutSparesBody(t *testing.T) {
+	useTempAppFolder(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(300 * time.Millisecond)
+		}
+		w.Write([]byte("first "))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("second"))
+	}))
+	defer srv.Close()
+
+	timeout := 150
+	r := Request{Method: "GET", URL: srv.URL + "/file", Settings: &RequestSettings{TimeoutMs: &timeout}}
+	download := func(r Request) ResponseMsg {
+		target := filepath.Join(t.TempDir(), "body")
+		dl := &downloadTarget{choose: func(string) (string, error) { return target, nil }}
+		resp, _ := NewApp().send(context.Background(), r, sendOptions{download: dl})
+		return resp
+	}
+	if resp := download(r); resp.Status != "200 OK" || resp.Size != int64(len("first second")) {
+		t.Errorf("slow body: status %q, %d bytes: %s", resp.Status, resp.Size, resp.Body)
+	}
+	r.URL = srv.URL + "/slow-headers"
+	if resp := download(r); resp.Status != "Error" {
+		t.Errorf("slow headers: status %q, want a timeout error", resp.Status)
+	}
+}
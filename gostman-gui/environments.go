@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Environment is a named set of variables (e.g. dev, staging, prod). At most
+// one environment is active at a time; its variables override those of the
+// request's folders and the globals (SavedData.Variables), which apply
+// whether or not an environment is active. See variableScopes for the full
+// order.
+type Environment struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Variables string `json:"variables"`
+}
+
+// activeEnvironment returns a pointer into data.Environments for the active
+// environment, or nil if none is active.
+func (data *SavedData) activeEnvironment() *Environment {
+	if data.ActiveEnvironmentId == "" {
+		return nil
+	}
+	return data.environment(data.ActiveEnvironmentId)
+}
+
+// environment returns a pointer into data.Environments, or nil if id is unknown.
+func (data *SavedData) environment(id string) *Environment {
+	for i := range data.Environments {
+		if data.Environments[i].Id == id {
+			return &data.Environments[i]
+		}
+	}
+	return nil
+}
+
+func validEnvironmentName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("environment name is required")
+	}
+	return name, nil
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+func (a *App) GetEnvironments() []Environment {
+	data := getSavedData()
+	result := make([]Environment, len(data.Environments))
+	copy(result, data.Environments)
+	return result
+}
+
+// GetActiveEnvironmentId returns the id of the active environment, or "" if
+// the top-level variables are in use.
+func (a *App) GetActiveEnvironmentId() string {
+	data := getSavedData()
+	if data.activeEnvironment() == nil {
+		return ""
+	}
+	return data.ActiveEnvironmentId
+}
+
+// CreateEnvironment adds an empty environment. It does not become active.
+func (a *App) CreateEnvironment(name string) (Environment, error) {
+	name, err := validEnvironmentName(name)
+	if err != nil {
+		return Environment{}, err
+	}
+
+	env := Environment{Id: uuid.New().String(), Name: name, Variables: "{}"}
+	if err := a.mutateSavedData(func(data *SavedData) {
+		data.Environments = append(data.Environments, env)
+	}); err != nil {
+		return Environment{}, err
+	}
+	return env, nil
+}
+
+func (a *App) RenameEnvironment(id, name string) error {
+	name, err := validEnvironmentName(name)
+	if err != nil {
+		return err
+	}
+
+	var notFound bool
+	if err := a.mutateSavedData(func(data *SavedData) {
+		env := data.environment(id)
+		if env == nil {
+			notFound = true
+			return
+		}
+		env.Name = name
+	}); err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("environment not found: %s", id)
+	}
+	return nil
+}
+
+// DuplicateEnvironment copies an environment's variables into a new
+// environment named "<name> Copy".
+func (a *App) DuplicateEnvironment(id string) (Environment, error) {
+	var dup Environment
+	var notFound bool
+	if err := a.mutateSavedData(func(data *SavedData) {
+		env := data.environment(id)
+		if env == nil {
+			notFound = true
+			return
+		}
+		dup = Environment{
+			Id:        uuid.New().String(),
+			Name:      env.Name + " Copy",
+			Variables: env.Variables,
+		}
+		data.Environments = append(data.Environments, dup)
+	}); err != nil {
+		return Environment{}, err
+	}
+	if notFound {
+		return Environment{}, fmt.Errorf("environment not found: %s", id)
+	}
+	return dup, nil
+}
+
+// DeleteEnvironment removes an environment with its proxy rules, cookies
+// and cached OAuth 2.0 tokens. Deleting the active environment falls back
+// to the top-level variables.
+func (a *App) DeleteEnvironment(id string) error {
+	var notFound bool
+	if err := a.mutateSavedData(func(data *SavedData) {
+		index := -1
+		for i, env := range data.Environments {
+			if env.Id == id {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			notFound = true
+			return
+		}
+		data.Environments = append(data.Environments[:index], data.Environments[index+1:]...)
+		if data.ActiveEnvironmentId == id {
+			data.ActiveEnvironmentId = ""
+		}
+		proxies := data.Proxies[:0]
+		for _, p := range data.Proxies {
+			if p.EnvironmentId != id {
+				proxies = append(proxies, p)
+			}
+		}
+		data.Proxies = proxies
+	}); err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("environment not found: %s", id)
+	}
+
+	// Its cookies and tokens can no longer be used but hold credentials
+	if err := mutateCookies(id, func([]JarCookie) []JarCookie { return nil }); err != nil {
+		return err
+	}
+	return mutateTokens(func(store tokenStore) {
+		delete(store, id)
+	})
+}
+
+// SetActiveEnvironment makes id the active environment. An empty id
+// deactivates environments so the top-level variables are used.
+func (a *App) SetActiveEnvironment(id string) error {
+	var notFound bool
+	if err := a.mutateSavedData(func(data *SavedData) {
+		if id != "" && data.environment(id) == nil {
+			notFound = true
+			return
+		}
+		data.ActiveEnvironmentId = id
+	}); err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("environment not found: %s", id)
+	}
+	return nil
+}
+
+// SaveEnvironmentVariables replaces the variables of any environment,
+// active or not, using the same validation as SaveVariables.
+func (a *App) SaveEnvironmentVariables(id, variableString string) error {
+	coerced, err := normalizeVariables(variableString)
+	if err != nil {
+		return err
+	}
+
+	var notFound bool
+	if err := a.mutateSavedData(func(data *SavedData) {
+		env := data.environment(id)
+		if env == nil {
+			notFound = true
+			return
+		}
+		env.Variables = coerced
+	}); err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("environment not found: %s", id)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Folder groups saved requests (Request.FolderId) into a collection. Folders
+// nest through ParentId and are ordered among their siblings by Order.
+// Headers, Variables and Auth are inherited by every request below the
+// folder, with nearer folders taking precedence over their ancestors.
+type Folder struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	ParentId    string    `json:"parentId"`
+	Order       int       `json:"order"`
+	Description string    `json:"description"`
+	Headers     KeyValues `json:"headers" ts_type:"string"`
+	Variables   string    `json:"variables"`
+	// Auth is inherited by requests and subfolders that do not set their own
+	Auth *AuthConfig `json:"auth"`
+}
+
+// folder returns a pointer into data.Folders, or nil if id is unknown.
+func (data *SavedData) folder(id string) *Folder {
+	if id == "" {
+		return nil
+	}
+	for i := range data.Folders {
+		if data.Folders[i].Id == id {
+			return &data.Folders[i]
+		}
+	}
+	return nil
+}
+
+// folderChain returns the folder with the given id followed by its
+// ancestors, nearest first. Unknown ids and parent cycles end the chain.
+func (data *SavedData) folderChain(id string) []Folder {
+	var chain []Folder
+	seen := map[string]bool{}
+	for f := data.folder(id); f != nil && !seen[f.Id]; f = data.folder(f.ParentId) {
+		seen[f.Id] = true
+		chain = append(chain, *f)
+	}
+	return chain
+}
+
+// folderSubtree returns the ids of the folder and all of its descendants.
+func (data *SavedData) folderSubtree(id string) map[string]bool {
+	ids := map[string]bool{id: true}
+	for changed := true; changed; {
+		changed = false
+		for _, f := range data.Folders {
+			if !ids[f.Id] && ids[f.ParentId] {
+				ids[f.Id] = true
+				changed = true
+			}
+		}
+	}
+	return ids
+}
+
+// folderHeaders merges the enabled Headers of the folder chain for folderId
+// so that nearer folders override their ancestors' headers of the same
+// name. Placeholders are substituted as for request headers.
+func folderHeaders(chain []Folder, variables map[string]string) KeyValues {
+	var merged KeyValues
+	for i := len(chain) - 1; i >= 0; i-- {
+		merged = merged.overrideHeaders(chain[i].Headers.resolved(variables))
+	}
+	return merged
+}
+
+// reorderSiblings moves the item at index from to position order among the
+// items for which sibling returns true, then renumbers those siblings
+// 0..n-1 via setOrder. Items are visited in their current Order.
+func reorderSiblings(n int, sibling func(i int) bool, getOrder func(i int) int, setOrder func(i, order int), moved, order int) {
+	var idx []int
+	for i := 0; i < n; i++ {
+		if i != moved && sibling(i) {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(x, y int) bool { return getOrder(idx[x]) < getOrder(idx[y]) })
+	if order < 0 || order > len(idx) {
+		order = len(idx)
+	}
+	idx = append(idx[:order], append([]int{moved}, idx[order:]...)...)
+	for pos, i := range idx {
+		setOrder(i, pos)
+	}
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetFolders returns all folders ordered by Order. Callers rebuild the tree
+// from ParentId.
+func (a *App) GetFolders() []Folder {
+	data := getSavedData()
+	result := make([]Folder, len(data.Folders))
+	copy(result, data.Folders)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Order < result[j].Order })
+	return result
+}
+
+// SaveFolder creates a folder (empty Id) or updates an existing one. New
+// folders are appended after their siblings. Updates only change the Name,
+// Description and Headers; folders are moved with MoveFolder and their
+// variables and auth are saved with SaveFolderVariables and SaveFolderAuth.
+func (a *App) SaveFolder(f Folder) (Folder, error) {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" {
+		return Folder{}, errors.New("folder name is required")
+	}
+
+	var saveErr error
+	err := a.mutateSavedData(func(data *SavedData) {
+		if existing := data.folder(f.Id); existing != nil {
+			existing.Name = f.Name
+			existing.Description = f.Description
+			existing.Headers = f.Headers
+			f = *existing
+			return
+		}
+		if f.ParentId != "" && data.folder(f.ParentId) == nil {
+			saveErr = fmt.Errorf("parent folder not found: %s", f.ParentId)
+			return
+		}
+		if f.Id == "" {
+			f.Id = uuid.New().String()
+		}
+		f.Order = 0
+		for _, sib := range data.Folders {
+			if sib.ParentId == f.ParentId && sib.Order >= f.Order {
+				f.Order = sib.Order + 1
+			}
+		}
+		data.Folders = append(data.Folders, f)
+	})
+	if err != nil {
+		return Folder{}, err
+	}
+	if saveErr != nil {
+		return Folder{}, saveErr
+	}
+	return f, nil
+}
+
+// DeleteFolder removes a folder and all of its subfolders. Requests inside
+// them are deleted when deleteRequests is true; otherwise they move to the
+// deleted folder's parent.
+func (a *App) DeleteFolder(id string, deleteRequests bool) error {
+	var notFound bool
+	err := a.mutateSavedData(func(data *SavedData) {
+		target := data.folder(id)
+		if target == nil {
+			notFound = true
+			return
+		}
+		parentId := target.ParentId
+		subtree := data.folderSubtree(id)
+
+		folders := data.Folders[:0]
+		for _, f := range data.Folders {
+			if !subtree[f.Id] {
+				folders = append(folders, f)
+			}
+		}
+		data.Folders = folders
+
+		requests := data.Requests[:0]
+		for _, r := range data.Requests {
+			if subtree[r.FolderId] {
+				if deleteRequests {
+					continue
+				}
+				r.FolderId = parentId
+			}
+			requests = append(requests, r)
+		}
+		data.Requests = requests
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("folder not found: %s", id)
+	}
+	return nil
+}
+
+// MoveRequest moves a saved request into folderId ("" for the root) at
+// position order among the requests already there. A negative or
+// out-of-range order appends it.
+func (a *App) MoveRequest(requestId, folderId string, order int) error {
+	var moveErr error
+	err := a.mutateSavedData(func(data *SavedData) {
+		if folderId != "" && data.folder(folderId) == nil {
+			moveErr = fmt.Errorf("folder not found: %s", folderId)
+			return
+		}
+		moved := -1
+		for i, r := range data.Requests {
+			if r.Id == requestId {
+				moved = i
+				break
+			}
+		}
+		if moved == -1 {
+			moveErr = fmt.Errorf("id not found: %s", requestId)
+			return
+		}
+		data.Requests[moved].FolderId = folderId
+		reorderSiblings(len(data.Requests),
+			func(i int) bool { return data.Requests[i].FolderId == folderId },
+			func(i int) int { return data.Requests[i].Order },
+			func(i, order int) { data.Requests[i].Order = order },
+			moved, order)
+	})
+	if err != nil {
+		return err
+	}
+	return moveErr
+}
+
+// MoveFolder moves a folder under parentId ("" for the root) at position
+// order among its new siblings.
+func (a *App) MoveFolder(id, parentId string, order int) error {
+	var moveErr error
+	err := a.mutateSavedData(func(data *SavedData) {
+		target := data.folder(id)
+		if target == nil {
+			moveErr = fmt.Errorf("folder not found: %s", id)
+			return
+		}
+		if parentId != "" {
+			if data.folder(parentId) == nil {
+				moveErr = fmt.Errorf("parent folder not found: %s", parentId)
+				return
+			}
+			if data.folderSubtree(id)[parentId] {
+				moveErr = errors.New("a folder cannot be moved into itself")
+				return
+			}
+		}
+		target.ParentId = parentId
+		moved := -1
+		for i := range data.Folders {
+			if data.Folders[i].Id == id {
+				moved = i
+			}
+		}
+		reorderSiblings(len(data.Folders),
+			func(i int) bool { return data.Folders[i].ParentId == parentId },
+			func(i int) int { return data.Folders[i].Order },
+			func(i, order int) { data.Folders[i].Order = order },
+			moved, order)
+	})
+	if err != nil {
+		return err
+	}
+	return moveErr
+}
+
+// GetFolderVariables returns the collection variables of a folder.
+func (a *App) GetFolderVariables(folderId string) string {
+	data := getSavedData()
+	if folder := data.folder(folderId); folder != nil && folder.Variables != "" {
+		return folder.Variables
+	}
+	return "{}"
+}
+
+// SaveFolderVariables replaces the collection variables of a folder.
+func (a *App) SaveFolderVariables(folderId, variableString string) error {
+	coerced, err := normalizeVariables(variableString)
+	if err != nil {
+		return err
+	}
+	return a.mutateFolder(folderId, func(folder *Folder) {
+		folder.Variables = coerced
+	})
+}
+
+// SaveFolderAuth replaces the auth a folder passes on to its requests and
+// subfolders. nil (or Type "inherit") inherits the parent folder's auth.
+func (a *App) SaveFolderAuth(folderId string, auth *AuthConfig) error {
+	return a.mutateFolder(folderId, func(folder *Folder) {
+		folder.Auth = auth
+	})
+}
+
+// mutateFolder runs fn on the saved folder with the given id.
+func (a *App) mutateFolder(folderId string, fn func(folder *Folder)) error {
+	var notFound bool
+	err := a.mutateSavedData(func(data *SavedData) {
+		folder := data.folder(folderId)
+		if folder == nil {
+			notFound = true
+			return
+		}
+		fn(folder)
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("folder not found: %s", folderId)
+	}
+	return nil
+}
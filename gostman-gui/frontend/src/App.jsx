@@ -1,6 +1,6 @@
 import { useEffect, useCallback, lazy, Suspense } from 'react'
 import { RotateCcw, Import, Loader2 } from "lucide-react"
-import { SendRequest, GetRequests, SaveRequest, DeleteRequest, GetVariables, SaveVariables, ResetData } from "../wailsjs/go/main/App"
+import { SendRequest, CancelRequest, GetRequests, SaveRequest, DeleteRequest, GetVariables, SaveVariables, ResetData } from "../wailsjs/go/main/App"
 import { Sidebar } from "./components/Sidebar"
 import { RequestBar } from "./components/RequestBar"
 import { ResponsePanel } from "./components/ResponsePanel"
@@ -201,13 +201,15 @@ function App() {
   }, [refreshRequests])
 
   const handleSend = useCallback(async () => {
-    updateActiveTab({ loading: true, status: 'Sending...', responseTime: null })
+    const requestId = crypto.randomUUID()
+    updateActiveTab({ loading: true, requestId, status: 'Sending...', responseTime: null })
     const startTime = performance.now()
 
     const getResponseTime = () => Math.round(performance.now() - startTime)
 
     try {
       const resp = await SendRequest(
+        requestId,
         activeRequest.method,
         activeRequest.url,
         activeRequest.headers,
@@ -241,6 +243,12 @@ function App() {
     }
   }, [activeRequest])
 
+  const handleCancel = useCallback(() => {
+    if (activeTab?.requestId) {
+      CancelRequest(activeTab.requestId).catch(() => {})
+    }
+  }, [activeTab?.requestId])
+
   const handleClearHistory = useCallback(() => {
     showConfirm(
       'Clear History',
@@ -448,6 +456,7 @@ function App() {
             onBodyChange={handleBodyChange}
             onQueryParamsChange={handleQueryParamsChange}
             onSend={handleSend}
+            onCancel={handleCancel}
             onSave={handleSave}
             onGenerateCode={handleGenerateCode}
             loading={activeTab?.loading || false}
@@ -1,5 +1,5 @@
 import React, { memo } from "react"
-import { Send, Save, Globe, Code, Radio } from "lucide-react"
+import { Send, Save, Globe, Code, Radio, X } from "lucide-react"
 import { Button } from "./ui/button"
 import { Badge } from "./ui/badge"
 import { Select } from "./ui/select"
@@ -10,7 +10,7 @@ import { isWebSocketURL } from "./WebSocketPanel"
 
 import { HTTP_METHODS, METHOD_COLORS } from "../lib/constants"
 
-export const RequestBar = memo(function RequestBar({ activeRequest, onMethodChange, onUrlChange, onNameChange, onHeadersChange, onBodyChange, onQueryParamsChange, onSend, onSave, onGenerateCode, loading }) {
+export const RequestBar = memo(function RequestBar({ activeRequest, onMethodChange, onUrlChange, onNameChange, onHeadersChange, onBodyChange, onQueryParamsChange, onSend, onCancel, onSave, onGenerateCode, loading }) {
   // Check if URL is a WebSocket URL
   const isWebSocket = activeRequest?.url && isWebSocketURL(activeRequest.url)
 
@@ -110,19 +110,19 @@ export const RequestBar = memo(function RequestBar({ activeRequest, onMethodChan
           )}
         </div>
 
-        {/* Send Button */}
+        {/* Send Button, cancels the request while it is sending */}
         <Button
-          onClick={onSend}
-          disabled={loading}
+          onClick={loading ? onCancel : onSend}
+          disabled={loading && !onCancel}
           size="sm"
           className={cn(
             "gap-2 font-medium",
             loading && "animate-pulse-glow"
           )}
-          title="Send request (Ctrl+Enter)"
+          title={loading ? "Cancel request" : "Send request (Ctrl+Enter)"}
         >
-          <Send className="h-4 w-4" />
-          {loading ? "Sending..." : "Send"}
+          {loading ? <X className="h-4 w-4" /> : <Send className="h-4 w-4" />}
+          {loading ? "Cancel" : "Send"}
         </Button>
 
         {/* Save Button */}
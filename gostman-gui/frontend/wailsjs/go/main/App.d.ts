@@ -2,16 +2,112 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function AddCABundle(arg1:string,arg2:string):Promise<main.CABundle>;
+
+export function AddCertificate(arg1:main.ClientCertificate):Promise<main.ClientCertificate>;
+
+export function CancelRequest(arg1:string):Promise<void>;
+
+export function ClearCookies(arg1:string):Promise<void>;
+
+export function ClearOAuth2Tokens():Promise<void>;
+
+export function CreateEnvironment(arg1:string):Promise<main.Environment>;
+
+export function DeleteCABundle(arg1:string):Promise<void>;
+
+export function DeleteCertificate(arg1:string):Promise<void>;
+
+export function DeleteCookie(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function DeleteEnvironment(arg1:string):Promise<void>;
+
+export function DeleteFolder(arg1:string,arg2:boolean):Promise<void>;
+
+export function DeleteHistoryEntry(arg1:string):Promise<void>;
+
+export function DeleteProxy(arg1:string):Promise<void>;
+
 export function DeleteRequest(arg1:string):Promise<void>;
 
+export function DownloadRequest(arg1:string,arg2:main.Request):Promise<main.ResponseMsg>;
+
+export function DuplicateEnvironment(arg1:string):Promise<main.Environment>;
+
+export function ExecuteRequest(arg1:string,arg2:main.Request):Promise<main.ResponseMsg>;
+
+export function GetActiveEnvironmentId():Promise<string>;
+
+export function GetCABundles():Promise<Array<main.CABundle>>;
+
+export function GetCertificates():Promise<Array<main.ClientCertificate>>;
+
+export function GetCookies(arg1:string):Promise<Array<main.JarCookie>>;
+
+export function GetEnvironments():Promise<Array<main.Environment>>;
+
+export function GetFolderVariables(arg1:string):Promise<string>;
+
+export function GetFolders():Promise<Array<main.Folder>>;
+
+export function GetGlobals():Promise<string>;
+
+export function GetHistory(arg1:main.HistoryFilter):Promise<Array<main.HistoryEntry>>;
+
+export function GetHistoryHosts():Promise<Array<string>>;
+
+export function GetInFlightRequests():Promise<Array<string>>;
+
+export function GetOAuth2Token(arg1:main.Request):Promise<main.OAuth2Token>;
+
+export function GetProxies():Promise<Array<main.ProxyConfig>>;
+
 export function GetRequests():Promise<Array<main.Request>>;
 
+export function GetSettings():Promise<main.Settings>;
+
 export function GetVariables():Promise<string>;
 
+export function MoveFolder(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function MoveRequest(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function PreviewURL(arg1:main.Request):Promise<string>;
+
+export function PurgeHistory(arg1:string):Promise<void>;
+
+export function RenameEnvironment(arg1:string,arg2:string):Promise<void>;
+
+export function RerunHistoryEntry(arg1:string):Promise<main.ResponseMsg>;
+
 export function ResetData():Promise<void>;
 
+export function ResetSettings():Promise<void>;
+
+export function ResolveVariables(arg1:main.Request):Promise<Array<main.ResolvedVariable>>;
+
+export function RunCollection(arg1:string,arg2:main.RunOptions):Promise<main.RunSummary>;
+
+export function SaveCookie(arg1:main.JarCookie):Promise<void>;
+
+export function SaveEnvironmentVariables(arg1:string,arg2:string):Promise<void>;
+
+export function SaveFolder(arg1:main.Folder):Promise<main.Folder>;
+
+export function SaveFolderAuth(arg1:string,arg2:main.AuthConfig):Promise<void>;
+
+export function SaveFolderVariables(arg1:string,arg2:string):Promise<void>;
+
+export function SaveGlobals(arg1:string):Promise<void>;
+
+export function SaveProxy(arg1:main.ProxyConfig):Promise<main.ProxyConfig>;
+
 export function SaveRequest(arg1:main.Request):Promise<string>;
 
+export function SaveSettings(arg1:main.Settings):Promise<void>;
+
 export function SaveVariables(arg1:string):Promise<string>;
 
-export function SendRequest(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<main.ResponseMsg>;
+export function SendRequest(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.ResponseMsg>;
+
+export function SetActiveEnvironment(arg1:string):Promise<void>;
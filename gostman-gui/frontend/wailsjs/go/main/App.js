@@ -2,30 +2,222 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddCABundle(arg1, arg2) {
+  return window['go']['main']['App']['AddCABundle'](arg1, arg2);
+}
+
+export function AddCertificate(arg1) {
+  return window['go']['main']['App']['AddCertificate'](arg1);
+}
+
+export function CancelRequest(arg1) {
+  return window['go']['main']['App']['CancelRequest'](arg1);
+}
+
+export function ClearCookies(arg1) {
+  return window['go']['main']['App']['ClearCookies'](arg1);
+}
+
+export function ClearOAuth2Tokens() {
+  return window['go']['main']['App']['ClearOAuth2Tokens']();
+}
+
+export function CreateEnvironment(arg1) {
+  return window['go']['main']['App']['CreateEnvironment'](arg1);
+}
+
+export function DeleteCABundle(arg1) {
+  return window['go']['main']['App']['DeleteCABundle'](arg1);
+}
+
+export function DeleteCertificate(arg1) {
+  return window['go']['main']['App']['DeleteCertificate'](arg1);
+}
+
+export function DeleteCookie(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteCookie'](arg1, arg2, arg3);
+}
+
+export function DeleteEnvironment(arg1) {
+  return window['go']['main']['App']['DeleteEnvironment'](arg1);
+}
+
+export function DeleteFolder(arg1, arg2) {
+  return window['go']['main']['App']['DeleteFolder'](arg1, arg2);
+}
+
+export function DeleteHistoryEntry(arg1) {
+  return window['go']['main']['App']['DeleteHistoryEntry'](arg1);
+}
+
+export function DeleteProxy(arg1) {
+  return window['go']['main']['App']['DeleteProxy'](arg1);
+}
+
 export function DeleteRequest(arg1) {
   return window['go']['main']['App']['DeleteRequest'](arg1);
 }
 
+export function DownloadRequest(arg1, arg2) {
+  return window['go']['main']['App']['DownloadRequest'](arg1, arg2);
+}
+
+export function DuplicateEnvironment(arg1) {
+  return window['go']['main']['App']['DuplicateEnvironment'](arg1);
+}
+
+export function ExecuteRequest(arg1, arg2) {
+  return window['go']['main']['App']['ExecuteRequest'](arg1, arg2);
+}
+
+export function GetActiveEnvironmentId() {
+  return window['go']['main']['App']['GetActiveEnvironmentId']();
+}
+
+export function GetCABundles() {
+  return window['go']['main']['App']['GetCABundles']();
+}
+
+export function GetCertificates() {
+  return window['go']['main']['App']['GetCertificates']();
+}
+
+export function GetCookies(arg1) {
+  return window['go']['main']['App']['GetCookies'](arg1);
+}
+
+export function GetEnvironments() {
+  return window['go']['main']['App']['GetEnvironments']();
+}
+
+export function GetFolderVariables(arg1) {
+  return window['go']['main']['App']['GetFolderVariables'](arg1);
+}
+
+export function GetFolders() {
+  return window['go']['main']['App']['GetFolders']();
+}
+
+export function GetGlobals() {
+  return window['go']['main']['App']['GetGlobals']();
+}
+
+export function GetHistory(arg1) {
+  return window['go']['main']['App']['GetHistory'](arg1);
+}
+
+export function GetHistoryHosts() {
+  return window['go']['main']['App']['GetHistoryHosts']();
+}
+
+export function GetInFlightRequests() {
+  return window['go']['main']['App']['GetInFlightRequests']();
+}
+
+export function GetOAuth2Token(arg1) {
+  return window['go']['main']['App']['GetOAuth2Token'](arg1);
+}
+
+export function GetProxies() {
+  return window['go']['main']['App']['GetProxies']();
+}
+
 export function GetRequests() {
   return window['go']['main']['App']['GetRequests']();
 }
 
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
 export function GetVariables() {
   return window['go']['main']['App']['GetVariables']();
 }
 
+export function MoveFolder(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveFolder'](arg1, arg2, arg3);
+}
+
+export function MoveRequest(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveRequest'](arg1, arg2, arg3);
+}
+
+export function PreviewURL(arg1) {
+  return window['go']['main']['App']['PreviewURL'](arg1);
+}
+
+export function PurgeHistory(arg1) {
+  return window['go']['main']['App']['PurgeHistory'](arg1);
+}
+
+export function RenameEnvironment(arg1, arg2) {
+  return window['go']['main']['App']['RenameEnvironment'](arg1, arg2);
+}
+
+export function RerunHistoryEntry(arg1) {
+  return window['go']['main']['App']['RerunHistoryEntry'](arg1);
+}
+
 export function ResetData() {
   return window['go']['main']['App']['ResetData']();
 }
 
+export function ResetSettings() {
+  return window['go']['main']['App']['ResetSettings']();
+}
+
+export function ResolveVariables(arg1) {
+  return window['go']['main']['App']['ResolveVariables'](arg1);
+}
+
+export function RunCollection(arg1, arg2) {
+  return window['go']['main']['App']['RunCollection'](arg1, arg2);
+}
+
+export function SaveCookie(arg1) {
+  return window['go']['main']['App']['SaveCookie'](arg1);
+}
+
+export function SaveEnvironmentVariables(arg1, arg2) {
+  return window['go']['main']['App']['SaveEnvironmentVariables'](arg1, arg2);
+}
+
+export function SaveFolder(arg1) {
+  return window['go']['main']['App']['SaveFolder'](arg1);
+}
+
+export function SaveFolderAuth(arg1, arg2) {
+  return window['go']['main']['App']['SaveFolderAuth'](arg1, arg2);
+}
+
+export function SaveFolderVariables(arg1, arg2) {
+  return window['go']['main']['App']['SaveFolderVariables'](arg1, arg2);
+}
+
+export function SaveGlobals(arg1) {
+  return window['go']['main']['App']['SaveGlobals'](arg1);
+}
+
+export function SaveProxy(arg1) {
+  return window['go']['main']['App']['SaveProxy'](arg1);
+}
+
 export function SaveRequest(arg1) {
   return window['go']['main']['App']['SaveRequest'](arg1);
 }
 
+export function SaveSettings(arg1) {
+  return window['go']['main']['App']['SaveSettings'](arg1);
+}
+
 export function SaveVariables(arg1) {
   return window['go']['main']['App']['SaveVariables'](arg1);
 }
 
-export function SendRequest(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['SendRequest'](arg1, arg2, arg3, arg4, arg5);
+export function SendRequest(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['SendRequest'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function SetActiveEnvironment(arg1) {
+  return window['go']['main']['App']['SetActiveEnvironment'](arg1);
 }
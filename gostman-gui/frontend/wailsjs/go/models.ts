@@ -1,5 +1,321 @@
 export namespace main {
-
+	
+	export class AWSConfig {
+	    accessKeyId: string;
+	    secretAccessKey: string;
+	    sessionToken: string;
+	    region: string;
+	    service: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AWSConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accessKeyId = source["accessKeyId"];
+	        this.secretAccessKey = source["secretAccessKey"];
+	        this.sessionToken = source["sessionToken"];
+	        this.region = source["region"];
+	        this.service = source["service"];
+	    }
+	}
+	export class OAuth2Config {
+	    grantType: string;
+	    tokenUrl: string;
+	    authUrl: string;
+	    clientId: string;
+	    clientSecret: string;
+	    scope: string;
+	    clientAuth: string;
+	    redirectPort: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OAuth2Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.grantType = source["grantType"];
+	        this.tokenUrl = source["tokenUrl"];
+	        this.authUrl = source["authUrl"];
+	        this.clientId = source["clientId"];
+	        this.clientSecret = source["clientSecret"];
+	        this.scope = source["scope"];
+	        this.clientAuth = source["clientAuth"];
+	        this.redirectPort = source["redirectPort"];
+	    }
+	}
+	export class AuthConfig {
+	    type: string;
+	    username: string;
+	    password: string;
+	    token: string;
+	    key: string;
+	    value: string;
+	    in: string;
+	    oauth2?: OAuth2Config;
+	    aws?: AWSConfig;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuthConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.token = source["token"];
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.in = source["in"];
+	        this.oauth2 = this.convertValues(source["oauth2"], OAuth2Config);
+	        this.aws = this.convertValues(source["aws"], AWSConfig);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CABundle {
+	    id: string;
+	    name: string;
+	    file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CABundle(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.file = source["file"];
+	    }
+	}
+	export class ClientCertificate {
+	    id: string;
+	    host: string;
+	    certFile: string;
+	    keyFile: string;
+	    pfxFile: string;
+	    passphrase: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClientCertificate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.host = source["host"];
+	        this.certFile = source["certFile"];
+	        this.keyFile = source["keyFile"];
+	        this.pfxFile = source["pfxFile"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class CookieInfo {
+	    name: string;
+	    value: string;
+	    domain: string;
+	    path: string;
+	    expires: string;
+	    secure: boolean;
+	    httpOnly: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CookieInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.value = source["value"];
+	        this.domain = source["domain"];
+	        this.path = source["path"];
+	        this.expires = source["expires"];
+	        this.secure = source["secure"];
+	        this.httpOnly = source["httpOnly"];
+	    }
+	}
+	export class DataRowResult {
+	    iteration: number;
+	    data: Record<string, string>;
+	    passed: number;
+	    failed: number;
+	    testsPassed: number;
+	    testsFailed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DataRowResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.iteration = source["iteration"];
+	        this.data = source["data"];
+	        this.passed = source["passed"];
+	        this.failed = source["failed"];
+	        this.testsPassed = source["testsPassed"];
+	        this.testsFailed = source["testsFailed"];
+	    }
+	}
+	export class Environment {
+	    id: string;
+	    name: string;
+	    variables: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Environment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.variables = source["variables"];
+	    }
+	}
+	export class Folder {
+	    id: string;
+	    name: string;
+	    parentId: string;
+	    order: number;
+	    description: string;
+	    headers: string;
+	    variables: string;
+	    auth?: AuthConfig;
+	
+	    static createFrom(source: any = {}) {
+	        return new Folder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.parentId = source["parentId"];
+	        this.order = source["order"];
+	        this.description = source["description"];
+	        this.headers = source["headers"];
+	        this.variables = source["variables"];
+	        this.auth = this.convertValues(source["auth"], AuthConfig);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FormField {
+	    key: string;
+	    value: string;
+	    type: string;
+	    contentType: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormField(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.type = source["type"];
+	        this.contentType = source["contentType"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class HeaderEntry {
+	    key: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HeaderEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	    }
+	}
+	export class Timing {
+	    dns: number;
+	    connect: number;
+	    tls: number;
+	    ttfb: number;
+	    download: number;
+	    total: number;
+	    reusedConnection: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Timing(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dns = source["dns"];
+	        this.connect = source["connect"];
+	        this.tls = source["tls"];
+	        this.ttfb = source["ttfb"];
+	        this.download = source["download"];
+	        this.total = source["total"];
+	        this.reusedConnection = source["reusedConnection"];
+	    }
+	}
+	export class RequestSettings {
+	    timeoutMs?: number;
+	    followRedirects?: boolean;
+	    maxRedirects?: number;
+	    insecureSkipVerify?: boolean;
+	    httpVersion?: string;
+	    disableDecompression?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RequestSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timeoutMs = source["timeoutMs"];
+	        this.followRedirects = source["followRedirects"];
+	        this.maxRedirects = source["maxRedirects"];
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	        this.httpVersion = source["httpVersion"];
+	        this.disableDecompression = source["disableDecompression"];
+	    }
+	}
 	export class Request {
 	    id: string;
 	    name: string;
@@ -9,11 +325,23 @@ export namespace main {
 	    body: string;
 	    queryParams: string;
 	    response: string;
-
+	    folderId: string;
+	    order: number;
+	    variables: string;
+	    pathParams: string;
+	    graphqlVariables: string;
+	    preRequestScript: string;
+	    testScript: string;
+	    settings?: RequestSettings;
+	    auth?: AuthConfig;
+	    bodyMode: string;
+	    form: FormField[];
+	    bodyFile: string;
+	
 	    static createFrom(source: any = {}) {
 	        return new Request(source);
 	    }
-
+	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
@@ -24,10 +352,123 @@ export namespace main {
 	        this.body = source["body"];
 	        this.queryParams = source["queryParams"];
 	        this.response = source["response"];
+	        this.folderId = source["folderId"];
+	        this.order = source["order"];
+	        this.variables = source["variables"];
+	        this.pathParams = source["pathParams"];
+	        this.graphqlVariables = source["graphqlVariables"];
+	        this.preRequestScript = source["preRequestScript"];
+	        this.testScript = source["testScript"];
+	        this.settings = this.convertValues(source["settings"], RequestSettings);
+	        this.auth = this.convertValues(source["auth"], AuthConfig);
+	        this.bodyMode = source["bodyMode"];
+	        this.form = this.convertValues(source["form"], FormField);
+	        this.bodyFile = source["bodyFile"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-
-	export class CookieInfo {
+	export class HistoryEntry {
+	    id: string;
+	    timestamp: string;
+	    request: Request;
+	    method: string;
+	    url: string;
+	    host: string;
+	    headers: HeaderEntry[];
+	    body: string;
+	    bodyTruncated: boolean;
+	    status: string;
+	    statusCode: number;
+	    durationMs: number;
+	    timing?: Timing;
+	    size: number;
+	    response: string;
+	    responseTruncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.timestamp = source["timestamp"];
+	        this.request = this.convertValues(source["request"], Request);
+	        this.method = source["method"];
+	        this.url = source["url"];
+	        this.host = source["host"];
+	        this.headers = this.convertValues(source["headers"], HeaderEntry);
+	        this.body = source["body"];
+	        this.bodyTruncated = source["bodyTruncated"];
+	        this.status = source["status"];
+	        this.statusCode = source["statusCode"];
+	        this.durationMs = source["durationMs"];
+	        this.timing = this.convertValues(source["timing"], Timing);
+	        this.size = source["size"];
+	        this.response = source["response"];
+	        this.responseTruncated = source["responseTruncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HistoryFilter {
+	    query: string;
+	    method: string;
+	    status: string;
+	    host: string;
+	    from: string;
+	    to: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = source["query"];
+	        this.method = source["method"];
+	        this.status = source["status"];
+	        this.host = source["host"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class JarCookie {
 	    name: string;
 	    value: string;
 	    domain: string;
@@ -35,11 +476,12 @@ export namespace main {
 	    expires: string;
 	    secure: boolean;
 	    httpOnly: boolean;
-
+	    hostOnly: boolean;
+	
 	    static createFrom(source: any = {}) {
-	        return new CookieInfo(source);
+	        return new JarCookie(source);
 	    }
-
+	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
@@ -49,43 +491,353 @@ export namespace main {
 	        this.expires = source["expires"];
 	        this.secure = source["secure"];
 	        this.httpOnly = source["httpOnly"];
+	        this.hostOnly = source["hostOnly"];
+	    }
+	}
+	export class KeyValue {
+	    key: string;
+	    value: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyValue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.value = source["value"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	
+	export class OAuth2Token {
+	    accessToken: string;
+	    tokenType: string;
+	    refreshToken: string;
+	    expiry: string;
+	    scope: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OAuth2Token(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accessToken = source["accessToken"];
+	        this.tokenType = source["tokenType"];
+	        this.refreshToken = source["refreshToken"];
+	        this.expiry = source["expiry"];
+	        this.scope = source["scope"];
+	    }
+	}
+	export class ProxyConfig {
+	    id: string;
+	    name: string;
+	    environmentId: string;
+	    enabled: boolean;
+	    url: string;
+	    username: string;
+	    password: string;
+	    hosts: string[];
+	    bypass: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProxyConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.environmentId = source["environmentId"];
+	        this.enabled = source["enabled"];
+	        this.url = source["url"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.hosts = source["hosts"];
+	        this.bypass = source["bypass"];
+	    }
+	}
+	export class RedirectHop {
+	    url: string;
+	    status: string;
+	    statusCode: number;
+	    location: string;
+	    setCookies: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RedirectHop(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.status = source["status"];
+	        this.statusCode = source["statusCode"];
+	        this.location = source["location"];
+	        this.setCookies = source["setCookies"];
+	    }
+	}
+	
+	
+	export class ResolvedVariable {
+	    name: string;
+	    value: string;
+	    scope: string;
+	    resolved: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResolvedVariable(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.value = source["value"];
+	        this.scope = source["scope"];
+	        this.resolved = source["resolved"];
+	    }
+	}
+	export class TestResult {
+	    name: string;
+	    passed: boolean;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.passed = source["passed"];
+	        this.error = source["error"];
 	    }
 	}
-
 	export class ResponseMsg {
 	    body: string;
 	    status: string;
 	    headers: HeaderEntry[];
 	    cookies: CookieInfo[];
 	    size: number;
-
+	    compressedSize: number;
+	    contentEncoding: string;
+	    timing?: Timing;
+	    remoteAddr: string;
+	    protocol: string;
+	    requestId: string;
+	    redirects: RedirectHop[];
+	    savedPath: string;
+	    bodyEncoding: string;
+	    binary: boolean;
+	    mediaType: string;
+	    charset: string;
+	    hexPreview: string;
+	    testResults: TestResult[];
+	    scriptError: string;
+	    scriptLogs: string[];
+	
 	    static createFrom(source: any = {}) {
 	        return new ResponseMsg(source);
 	    }
-
+	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.body = source["body"];
 	        this.status = source["status"];
-	        this.headers = source["headers"];
-	        this.cookies = source["cookies"];
+	        this.headers = this.convertValues(source["headers"], HeaderEntry);
+	        this.cookies = this.convertValues(source["cookies"], CookieInfo);
 	        this.size = source["size"];
+	        this.compressedSize = source["compressedSize"];
+	        this.contentEncoding = source["contentEncoding"];
+	        this.timing = this.convertValues(source["timing"], Timing);
+	        this.remoteAddr = source["remoteAddr"];
+	        this.protocol = source["protocol"];
+	        this.requestId = source["requestId"];
+	        this.redirects = this.convertValues(source["redirects"], RedirectHop);
+	        this.savedPath = source["savedPath"];
+	        this.bodyEncoding = source["bodyEncoding"];
+	        this.binary = source["binary"];
+	        this.mediaType = source["mediaType"];
+	        this.charset = source["charset"];
+	        this.hexPreview = source["hexPreview"];
+	        this.testResults = this.convertValues(source["testResults"], TestResult);
+	        this.scriptError = source["scriptError"];
+	        this.scriptLogs = source["scriptLogs"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-
-	export class HeaderEntry {
-	    key: string;
-	    value: string;
-
+	export class RunOptions {
+	    folderId: string;
+	    iterations: number;
+	    delayMs: number;
+	    stopOnFailure: boolean;
+	    dataFile: string;
+	
 	    static createFrom(source: any = {}) {
-	        return new HeaderEntry(source);
+	        return new RunOptions(source);
 	    }
-
+	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.key = source["key"];
-	        this.value = source["value"];
+	        this.folderId = source["folderId"];
+	        this.iterations = source["iterations"];
+	        this.delayMs = source["delayMs"];
+	        this.stopOnFailure = source["stopOnFailure"];
+	        this.dataFile = source["dataFile"];
+	    }
+	}
+	export class RunResult {
+	    iteration: number;
+	    requestId: string;
+	    name: string;
+	    method: string;
+	    url: string;
+	    status: string;
+	    statusCode: number;
+	    durationMs: number;
+	    size: number;
+	    passed: boolean;
+	    error: string;
+	    testResults: TestResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RunResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.iteration = source["iteration"];
+	        this.requestId = source["requestId"];
+	        this.name = source["name"];
+	        this.method = source["method"];
+	        this.url = source["url"];
+	        this.status = source["status"];
+	        this.statusCode = source["statusCode"];
+	        this.durationMs = source["durationMs"];
+	        this.size = source["size"];
+	        this.passed = source["passed"];
+	        this.error = source["error"];
+	        this.testResults = this.convertValues(source["testResults"], TestResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RunSummary {
+	    runId: string;
+	    folderId: string;
+	    iterations: number;
+	    requests: number;
+	    passed: number;
+	    failed: number;
+	    tests: number;
+	    testsPassed: number;
+	    testsFailed: number;
+	    durationMs: number;
+	    avgResponseMs: number;
+	    stopped: boolean;
+	    cancelled: boolean;
+	    results: RunResult[];
+	    dataRows: DataRowResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RunSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.runId = source["runId"];
+	        this.folderId = source["folderId"];
+	        this.iterations = source["iterations"];
+	        this.requests = source["requests"];
+	        this.passed = source["passed"];
+	        this.failed = source["failed"];
+	        this.tests = source["tests"];
+	        this.testsPassed = source["testsPassed"];
+	        this.testsFailed = source["testsFailed"];
+	        this.durationMs = source["durationMs"];
+	        this.avgResponseMs = source["avgResponseMs"];
+	        this.stopped = source["stopped"];
+	        this.cancelled = source["cancelled"];
+	        this.results = this.convertValues(source["results"], RunResult);
+	        this.dataRows = this.convertValues(source["dataRows"], DataRowResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Settings {
+	    timeoutMs: number;
+	    followRedirects: boolean;
+	    maxRedirects: number;
+	    insecureSkipVerify: boolean;
+	    httpVersion: string;
+	    disableDecompression: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timeoutMs = source["timeoutMs"];
+	        this.followRedirects = source["followRedirects"];
+	        this.maxRedirects = source["maxRedirects"];
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	        this.httpVersion = source["httpVersion"];
+	        this.disableDecompression = source["disableDecompression"];
 	    }
 	}
+	
 
 }
+
@@ -0,0 +1,727 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// History limits. Oldest entries are dropped once maxHistoryEntries is
+// reached; request and response bodies are truncated to maxHistoryBodyBytes.
+const (
+	maxHistoryEntries   = 500
+	maxHistoryBodyBytes = 16 * 1024
+)
+
+// HistoryEntry is one executed request as it was sent, plus a summary of
+// the response. Request holds the unresolved request so it can be re-run
+// against the current variables. Credentials are masked in both (see
+// redactCredentials and redactedRequest).
+type HistoryEntry struct {
+	Id            string        `json:"id"`
+	Timestamp     string        `json:"timestamp"`
+	Request       Request       `json:"request"`
+	Method        string        `json:"method"`
+	URL           string        `json:"url"`
+	Host          string        `json:"host"`
+	Headers       []HeaderEntry `json:"headers"`
+	Body          string        `json:"body"`
+	BodyTruncated bool          `json:"bodyTruncated"`
+	Status        string        `json:"status"`
+	StatusCode    int           `json:"statusCode"`
+	DurationMs    int64         `json:"durationMs"`
+	Timing        *Timing       `json:"timing"`
+	Size          int64         `json:"size"`
+	Response      string        `json:"response"`
+	// Set when Response was cut at maxHistoryBodyBytes
+	ResponseTruncated bool `json:"responseTruncated"`
+}
+
+// HistoryFilter selects history entries. Empty fields match everything.
+type HistoryFilter struct {
+	// Query is matched case-insensitively against the URL, request name
+	// and request/response bodies.
+	Query  string `json:"query"`
+	Method string `json:"method"`
+	// Status is an exact code ("404"), a class ("2xx") or "error" for
+	// requests that failed without a response.
+	Status string `json:"status"`
+	Host   string `json:"host"`
+	// From and To bound the timestamp, as RFC 3339 or YYYY-MM-DD. To is
+	// inclusive of the whole day when given as a date.
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Limit int    `json:"limit"`
+}
+
+// The history is stored as JSON lines, one entry per line and oldest
+// first, so recording a send only appends its entry. Once the file holds
+// twice maxHistoryEntries lines it is compacted to the newest entries.
+var historyMutex sync.Mutex
+
+// historyCount caches the number of lines in the history file at
+// historyCountPath, so appends know when to compact without reading it.
+// Guarded by historyMutex.
+var (
+	historyCount     int
+	historyCountPath string
+)
+
+func historyFilePath() string {
+	return filepath.Join(appFolder, "history.jsonl")
+}
+
+// legacyHistoryFilePath is the JSON array older versions rewrote on every
+// send; see migrateHistory.
+func legacyHistoryFilePath() string {
+	return filepath.Join(appFolder, "history.json")
+}
+
+// migrateHistory converts the history file of older versions, if any.
+// Callers must hold historyMutex.
+func migrateHistory() error {
+	file, err := os.ReadFile(legacyHistoryFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+	if _, err := os.Stat(historyFilePath()); errors.Is(err, fs.ErrNotExist) {
+		var entries []HistoryEntry
+		if len(file) > 0 {
+			if err := json.Unmarshal(file, &entries); err != nil {
+				return fmt.Errorf("failed to unmarshal history: %w", err)
+			}
+		}
+		if err := writeHistory(entries); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(legacyHistoryFilePath()); err != nil {
+		return fmt.Errorf("failed to remove old history file: %w", err)
+	}
+	return nil
+}
+
+// readHistory loads the newest maxHistoryEntries entries of the history
+// file. Callers must hold historyMutex.
+func readHistory() ([]HistoryEntry, error) {
+	if err := migrateHistory(); err != nil {
+		return nil, err
+	}
+	file, err := os.ReadFile(historyFilePath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	var entries []HistoryEntry
+	lines := 0
+	for _, line := range bytes.Split(file, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines++
+		var e HistoryEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			// Left cut short by a crash while appending
+			log.Printf("Skipping unreadable history entry: %v", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	historyCount, historyCountPath = lines, historyFilePath()
+	if len(entries) > maxHistoryEntries {
+		entries = entries[len(entries)-maxHistoryEntries:]
+	}
+	return entries, nil
+}
+
+// writeHistory replaces the history file with entries. Callers must hold
+// historyMutex.
+func writeHistory(entries []HistoryEntry) error {
+	if err := os.MkdirAll(appFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	var encoded bytes.Buffer
+	enc := json.NewEncoder(&encoded)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	}
+	if err := os.WriteFile(historyFilePath(), encoded.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(historyFilePath(), 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	historyCount, historyCountPath = len(entries), historyFilePath()
+	return nil
+}
+
+// appendHistory adds entry at the end of the history file, compacting the
+// file when it grew too long.
+func appendHistory(entry HistoryEntry) error {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	if err := migrateHistory(); err != nil {
+		return err
+	}
+	if historyCountPath != historyFilePath() {
+		if _, err := readHistory(); err != nil {
+			return err
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if err := os.MkdirAll(appFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.OpenFile(historyFilePath(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	// Start on a new line if a crash cut the last entry short
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte("\n"), line...)
+		}
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	historyCount++
+	if historyCount >= 2*maxHistoryEntries {
+		entries, err := readHistory()
+		if err != nil {
+			return err
+		}
+		return writeHistory(entries)
+	}
+	return nil
+}
+
+// mutateHistory runs fn over the history under historyMutex and writes the
+// result back, like mutateSavedData does for gostman.json.
+func mutateHistory(fn func(entries []HistoryEntry) []HistoryEntry) error {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+	return writeHistory(fn(entries))
+}
+
+// truncateBody cuts s to maxHistoryBodyBytes without splitting a UTF-8
+// sequence.
+func truncateBody(s string) (string, bool) {
+	if len(s) <= maxHistoryBodyBytes {
+		return s, false
+	}
+	cut := maxHistoryBodyBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut], true
+}
+
+// redactedValue replaces credentials recorded in the history.
+const redactedValue = "REDACTED"
+
+// credentialHeaders and credentialParams are the header and query param
+// names (lower case) whose values are never recorded in the history. Form
+// fields are matched against credentialParams too.
+var (
+	credentialHeaders = map[string]bool{
+		"authorization":        true,
+		"proxy-authorization":  true,
+		"cookie":               true,
+		"x-api-key":            true,
+		"api-key":              true,
+		"x-auth-token":         true,
+		"x-amz-security-token": true,
+	}
+	credentialParams = map[string]bool{
+		"access_token":  true,
+		"api_key":       true,
+		"api-key":       true,
+		"apikey":        true,
+		"client_secret": true,
+		"password":      true,
+		"token":         true,
+	}
+)
+
+// credentialNames tells which headers and query params hold credentials:
+// the usual names, plus the name the API key auth of the send used.
+type credentialNames struct {
+	apiKeyHeader string
+	apiKeyParam  string
+}
+
+func newCredentialNames(auth AuthConfig) credentialNames {
+	var c credentialNames
+	if auth.Type == AuthAPIKey {
+		if auth.In == "query" {
+			c.apiKeyParam = auth.Key
+		} else {
+			c.apiKeyHeader = auth.Key
+		}
+	}
+	return c
+}
+
+func (c credentialNames) isHeader(name string) bool {
+	return credentialHeaders[strings.ToLower(name)] || (c.apiKeyHeader != "" && strings.EqualFold(name, c.apiKeyHeader))
+}
+
+func (c credentialNames) isParam(name string) bool {
+	return credentialParams[strings.ToLower(name)] || (c.apiKeyParam != "" && name == c.apiKeyParam)
+}
+
+// redactCredentials masks the credential headers and query params of s,
+// including those the auth put on the request.
+func (s *sentRequest) redactCredentials(auth AuthConfig) {
+	s.secrets = newCredentialNames(auth)
+	for name, values := range s.headers {
+		if s.secrets.isHeader(name) {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+
+	// Rewrite the query pair by pair to keep the rest of the URL as sent
+	rest, fragment, hasFragment := strings.Cut(s.url, "#")
+	base, query, hasQuery := strings.Cut(rest, "?")
+	if !hasQuery {
+		return
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		rawKey, _, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if s.secrets.isParam(key) {
+			pairs[i] = rawKey + "=" + redactedValue
+		}
+	}
+	s.url = base + "?" + strings.Join(pairs, "&")
+	if hasFragment {
+		s.url += "#" + fragment
+	}
+}
+
+// printableFieldValue returns the value of a form field as recorded in the
+// history: masked for fields named like credential query params.
+func printableFieldValue(key, value string) string {
+	if credentialParams[strings.ToLower(strings.TrimSpace(key))] {
+		return redactedValue
+	}
+	return value
+}
+
+// redactSecret masks a saved credential unless it only references
+// variables, which hold no secret and keep the entry re-runnable.
+func redactSecret(s string) string {
+	if strings.TrimSpace(placeholderRe.ReplaceAllString(s, "")) == "" {
+		return s
+	}
+	return redactedValue
+}
+
+// redactRows returns a copy of rows with the values of the rows named by
+// isSecret masked.
+func redactRows(rows KeyValues, isSecret func(name string) bool) KeyValues {
+	if rows.parseError() != nil {
+		return nil
+	}
+	out := make(KeyValues, len(rows))
+	for i, kv := range rows {
+		if isSecret(strings.TrimSpace(kv.Key)) {
+			kv.Value = redactSecret(kv.Value)
+		}
+		out[i] = kv
+	}
+	return out
+}
+
+// redactFields returns a copy of the form fields with the values of text
+// fields named like credential query params masked.
+func redactFields(fields []FormField) []FormField {
+	if fields == nil {
+		return nil
+	}
+	out := make([]FormField, len(fields))
+	for i, f := range fields {
+		if f.Type != FieldFile && credentialParams[strings.ToLower(strings.TrimSpace(f.Key))] {
+			f.Value = redactSecret(f.Value)
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// redactedRequest returns a copy of r for the history, with the secrets of
+// its auth and its credential header, param and form field rows masked.
+func redactedRequest(r Request, secrets credentialNames) Request {
+	if r.Auth != nil {
+		auth := *r.Auth
+		auth.Password = redactSecret(auth.Password)
+		auth.Token = redactSecret(auth.Token)
+		auth.Value = redactSecret(auth.Value)
+		if auth.OAuth2 != nil {
+			oauth2 := *auth.OAuth2
+			oauth2.ClientSecret = redactSecret(oauth2.ClientSecret)
+			auth.OAuth2 = &oauth2
+		}
+		if auth.AWS != nil {
+			aws := *auth.AWS
+			aws.SecretAccessKey = redactSecret(aws.SecretAccessKey)
+			aws.SessionToken = redactSecret(aws.SessionToken)
+			auth.AWS = &aws
+		}
+		r.Auth = &auth
+	}
+	r.Headers = redactRows(r.Headers, secrets.isHeader)
+	r.QueryParams = redactRows(r.QueryParams, secrets.isParam)
+	r.Form = redactFields(r.Form)
+	return r
+}
+
+// restoreRedacted returns the history copy r with the values
+// redactedRequest masked taken from saved, the request as it is saved now
+// (nil if it is not). Auth values are matched by field, and rows and form
+// fields by name and position among the rows of that name.
+func restoreRedacted(r Request, saved *Request) (Request, error) {
+	if saved == nil {
+		saved = &Request{}
+	}
+	missing := errors.New("the credentials of this history entry were redacted and its saved request no longer holds them")
+	if saved.Id == "" {
+		missing = errors.New("the credentials of this history entry were redacted and its request is not saved")
+	}
+
+	if r.Auth != nil {
+		auth := r.Auth.clone()
+		fields := auth.templateFields()
+		var savedFields []*string
+		if saved.Auth != nil && saved.Auth.Type == auth.Type {
+			savedFields = saved.Auth.templateFields()
+		}
+		for i, field := range fields {
+			if *field != redactedValue {
+				continue
+			}
+			if len(savedFields) != len(fields) {
+				return Request{}, missing
+			}
+			*field = *savedFields[i]
+		}
+		r.Auth = &auth
+	}
+
+	row := func(kv *KeyValue) (string, *string) { return kv.Key, &kv.Value }
+	var ok [3]bool
+	r.Headers, ok[0] = restoreValues(r.Headers, saved.Headers, row)
+	r.QueryParams, ok[1] = restoreValues(r.QueryParams, saved.QueryParams, row)
+	r.Form, ok[2] = restoreValues(r.Form, saved.Form, func(f *FormField) (string, *string) { return f.Key, &f.Value })
+	if !ok[0] || !ok[1] || !ok[2] {
+		return Request{}, missing
+	}
+	return r, nil
+}
+
+// restoreValues returns a copy of items with their redacted values taken
+// from the saved item of the same name and position among the items of
+// that name. field returns the name and value of an item. It reports false
+// if a redacted value has no saved counterpart.
+func restoreValues[T any](items, saved []T, field func(item *T) (string, *string)) ([]T, bool) {
+	if items == nil {
+		return nil, true
+	}
+	out := slices.Clone(items)
+	seen := map[string]int{}
+	for i := range out {
+		key, value := field(&out[i])
+		key = strings.ToLower(strings.TrimSpace(key))
+		n := seen[key]
+		seen[key]++
+		if *value != redactedValue {
+			continue
+		}
+		found := false
+		for j := range saved {
+			savedKey, savedValue := field(&saved[j])
+			if strings.ToLower(strings.TrimSpace(savedKey)) != key {
+				continue
+			}
+			if n == 0 {
+				*value, found = *savedValue, true
+				break
+			}
+			n--
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// recordHistory appends an entry for a request that reached the network.
+func recordHistory(r Request, sent *sentRequest, resp ResponseMsg) error {
+	r.Response = ""
+	entry := HistoryEntry{
+		Id:         uuid.New().String(),
+		Timestamp:  time.Now().UTC().Format(time.RFC3339Nano),
+		Request:    redactedRequest(r, sent.secrets),
+		Method:     sent.method,
+		URL:        sent.url,
+		Status:     resp.Status,
+		StatusCode: statusCodeOf(resp.Status),
+		DurationMs: sent.duration.Milliseconds(),
+		Timing:     resp.Timing,
+		Size:       resp.Size,
+	}
+	if u, err := url.Parse(sent.url); err == nil {
+		entry.Host = u.Host
+	}
+	for key, values := range sent.headers {
+		for _, v := range values {
+			entry.Headers = append(entry.Headers, HeaderEntry{Key: key, Value: v})
+		}
+	}
+	entry.Body, entry.BodyTruncated = truncateBody(sent.body)
+	entry.Response, entry.ResponseTruncated = truncateBody(resp.Body)
+
+	return appendHistory(entry)
+}
+
+// statusCodeOf extracts the numeric code from a status such as
+// "404 Not Found". It returns 0 for local failures like "Error".
+func statusCodeOf(status string) int {
+	code, _ := strconv.Atoi(strings.SplitN(status, " ", 2)[0])
+	return code
+}
+
+// parseHistoryTime parses an RFC 3339 timestamp or a YYYY-MM-DD date. When
+// endOfDay is set, a bare date is extended to the end of that day.
+func parseHistoryTime(s string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", s)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func matchesStatus(filter string, e HistoryEntry) bool {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	switch {
+	case filter == "":
+		return true
+	case filter == "error":
+		return e.StatusCode == 0
+	case len(filter) == 3 && strings.HasSuffix(filter, "xx"):
+		return e.StatusCode/100 == int(filter[0]-'0')
+	default:
+		return strconv.Itoa(e.StatusCode) == filter
+	}
+}
+
+func (f HistoryFilter) matches(e HistoryEntry, from, to time.Time) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, e.Method) {
+		return false
+	}
+	if f.Host != "" && !strings.EqualFold(f.Host, e.Host) {
+		return false
+	}
+	if !matchesStatus(f.Status, e) {
+		return false
+	}
+	if !from.IsZero() || !to.IsZero() {
+		ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+		if err != nil || (!from.IsZero() && ts.Before(from)) || (!to.IsZero() && ts.After(to)) {
+			return false
+		}
+	}
+	if q := strings.ToLower(f.Query); q != "" {
+		haystack := strings.ToLower(strings.Join([]string{e.URL, e.Request.Name, e.Body, e.Response}, "\n"))
+		if !strings.Contains(haystack, q) {
+			return false
+		}
+	}
+	return true
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetHistory returns the history entries matching filter, newest first.
+func (a *App) GetHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	var from, to time.Time
+	var err error
+	if filter.From != "" {
+		if from, err = parseHistoryTime(filter.From, false); err != nil {
+			return nil, err
+		}
+	}
+	if filter.To != "" {
+		if to, err = parseHistoryTime(filter.To, true); err != nil {
+			return nil, err
+		}
+	}
+
+	historyMutex.Lock()
+	entries, err := readHistory()
+	historyMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []HistoryEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if filter.matches(entries[i], from, to) {
+			result = append(result, entries[i])
+			if filter.Limit > 0 && len(result) == filter.Limit {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// RerunHistoryEntry sends the request of a history entry again, as it was
+// recorded, resolving its variables against the current scopes. The
+// credentials redacted in the entry are taken from the saved request; an
+// entry with redacted credentials whose request is no longer saved cannot
+// be re-run.
+func (a *App) RerunHistoryEntry(id string) (ResponseMsg, error) {
+	historyMutex.Lock()
+	entries, err := readHistory()
+	historyMutex.Unlock()
+	if err != nil {
+		return ResponseMsg{}, err
+	}
+	for _, e := range entries {
+		if e.Id != id {
+			continue
+		}
+		var saved *Request
+		if e.Request.Id != "" {
+			data := getSavedData()
+			for i := range data.Requests {
+				if data.Requests[i].Id == e.Request.Id {
+					saved = &data.Requests[i]
+					break
+				}
+			}
+		}
+		r, err := restoreRedacted(e.Request, saved)
+		if err != nil {
+			return ResponseMsg{}, err
+		}
+		return a.ExecuteRequest("", r), nil
+	}
+	return ResponseMsg{}, fmt.Errorf("history entry not found: %s", id)
+}
+
+func (a *App) DeleteHistoryEntry(id string) error {
+	var notFound bool
+	err := mutateHistory(func(entries []HistoryEntry) []HistoryEntry {
+		for i, e := range entries {
+			if e.Id == id {
+				return append(entries[:i], entries[i+1:]...)
+			}
+		}
+		notFound = true
+		return entries
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("history entry not found: %s", id)
+	}
+	return nil
+}
+
+// PurgeHistory deletes entries older than before (RFC 3339 or YYYY-MM-DD).
+// An empty before clears the whole history.
+func (a *App) PurgeHistory(before string) error {
+	var cutoff time.Time
+	if before != "" {
+		var err error
+		if cutoff, err = parseHistoryTime(before, false); err != nil {
+			return err
+		}
+	}
+	return mutateHistory(func(entries []HistoryEntry) []HistoryEntry {
+		if cutoff.IsZero() {
+			return nil
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if ts, err := time.Parse(time.RFC3339Nano, e.Timestamp); err == nil && ts.Before(cutoff) {
+				continue
+			}
+			kept = append(kept, e)
+		}
+		return kept
+	})
+}
+
+// GetHistoryHosts returns the distinct hosts in the history, for the host
+// filter drop-down.
+func (a *App) GetHistoryHosts() ([]string, error) {
+	historyMutex.Lock()
+	entries, err := readHistory()
+	historyMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	hosts := []string{}
+	for _, e := range entries {
+		if e.Host != "" && !seen[e.Host] {
+			seen[e.Host] = true
+			hosts = append(hosts, e.Host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// useTempAppFolder points the app data files at a temporary directory for
+// the duration of the test.
+func useTempAppFolder(t *testing.T) {
+	t.Helper()
+	oldFolder, oldPath := appFolder, jsonfilePath
+	appFolder = t.TempDir()
+	jsonfilePath = filepath.Join(appFolder, "gostman.json")
+	t.Cleanup(func() { appFolder, jsonfilePath = oldFolder, oldPath })
+}
+
+func TestHistoryRedactsCredentials(t *testing.T) {
+	useTempAppFolder(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"secret-oauth-token","token_type":"Bearer"}`))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	rows := KeyValues{
+		{Key: "Authorization", Value: "Bearer secret-typed-token", Enabled: true},
+		{Key: "X-Api-Key", Value: "secret-typed-key", Enabled: true},
+		{Key: "Accept", Value: "text/plain", Enabled: true},
+	}
+	requests := []Request{
+		{Method: "GET", URL: srv.URL, Headers: rows,
+			QueryParams: KeyValues{{Key: "access_token", Value: "secret-param", Enabled: true}},
+			Auth:        &AuthConfig{Type: AuthBasic, Username: "user", Password: "secret-password"}},
+		{Method: "GET", URL: srv.URL + "?a=1",
+			Auth: &AuthConfig{Type: AuthAPIKey, Key: "sig", Value: "secret-query-key", In: "query"}},
+		{Method: "GET", URL: srv.URL,
+			Auth: &AuthConfig{Type: AuthAPIKey, Key: "X-Custom", Value: "secret-header-key"}},
+		{Method: "GET", URL: srv.URL,
+			Auth: &AuthConfig{Type: AuthBearer, Token: "secret-bearer"}},
+		{Method: "GET", URL: srv.URL,
+			Auth: &AuthConfig{Type: AuthAWSV4, AWS: &AWSConfig{AccessKeyId: "AKID", SecretAccessKey: "secret-aws-key",
+				SessionToken: "secret-aws-session", Region: "us-east-1", Service: "execute-api"}}},
+		{Method: "GET", URL: srv.URL,
+			Auth: &AuthConfig{Type: AuthOAuth2, OAuth2: &OAuth2Config{GrantType: GrantClientCredentials,
+				TokenURL: srv.URL + "/token", ClientId: "client", ClientSecret: "secret-client"}}},
+		{Method: "POST", URL: srv.URL, BodyMode: BodyURLEncoded, Auth: &AuthConfig{Type: AuthNone},
+			Form: []FormField{{Key: "user", Value: "me", Enabled: true}, {Key: "Password", Value: "secret-form-password", Enabled: true}}},
+		{Method: "POST", URL: srv.URL, BodyMode: BodyMultipart, Auth: &AuthConfig{Type: AuthNone},
+			Form: []FormField{{Key: "client_secret", Value: "secret-form-client", Enabled: true}}},
+	}
+	a := NewApp()
+	for _, r := range requests {
+		if resp, _ := a.sendRecorded(context.Background(), r, sendOptions{}); resp.Status != "200 OK" {
+			t.Fatalf("%s: status %q: %s", r.Auth.Type, resp.Status, resp.Body)
+		}
+	}
+
+	contents, err := os.ReadFile(historyFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(contents), "secret-") {
+		t.Errorf("history.json holds a credential:\n%s", contents)
+	}
+	if !strings.Contains(string(contents), redactedValue) {
+		t.Error("history.json has no redacted values")
+	}
+	info, err := os.Stat(historyFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("history.json mode = %v, want 0600", mode)
+	}
+}
+
+func TestRedactedRequestKeepsPlaceholders(t *testing.T) {
+	r := Request{
+		Auth:    &AuthConfig{Type: AuthBearer, Token: "{{token}}"},
+		Headers: KeyValues{{Key: "Authorization", Value: "Bearer {{token}}", Enabled: true}},
+	}
+	got := redactedRequest(r, credentialNames{})
+	if got.Auth.Token != "{{token}}" {
+		t.Errorf("Token = %q, want the placeholder kept", got.Auth.Token)
+	}
+	if v := got.Headers[0].Value; v != redactedValue {
+		t.Errorf("Authorization row = %q, want %q", v, redactedValue)
+	}
+}
+
+func TestRerunHistoryEntrySendsCredentials(t *testing.T) {
+	useTempAppFolder(t)
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		received = append(received, r.URL.Path+" "+user+":"+password+" "+r.Header.Get("X-Api-Key"))
+	}))
+	defer srv.Close()
+
+	a := NewApp()
+	saved := Request{Id: "saved", Method: "GET", URL: srv.URL + "/sent",
+		Headers: KeyValues{{Key: "X-Api-Key", Value: "s3cret", Enabled: true}},
+		Auth:    &AuthConfig{Type: AuthBasic, Username: "u", Password: "pw"}}
+	a.SaveRequest(saved)
+	a.ExecuteRequest("", saved)
+	unsaved := saved
+	unsaved.Id = ""
+	a.ExecuteRequest("", unsaved)
+
+	// Editing the saved request does not change what a re-run sends
+	edited := saved
+	edited.URL = srv.URL + "/edited"
+	a.SaveRequest(edited)
+
+	entries, err := a.GetHistory(HistoryFilter{})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("got %d history entries: %v", len(entries), err)
+	}
+	if _, err := a.RerunHistoryEntry(entries[0].Id); err == nil {
+		t.Error("re-running an unsaved request with redacted credentials succeeded")
+	}
+	if resp, err := a.RerunHistoryEntry(entries[1].Id); err != nil || resp.Status != "200 OK" {
+		t.Fatalf("re-run: %q, %v", resp.Status, err)
+	}
+	if err := a.DeleteRequest(saved.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RerunHistoryEntry(entries[1].Id); err == nil {
+		t.Error("re-running a deleted request with redacted credentials succeeded")
+	}
+
+	want := []string{"/sent u:pw s3cret", "/sent u:pw s3cret", "/sent u:pw s3cret"}
+	if strings.Join(received, "|") != strings.Join(want, "|") {
+		t.Errorf("server received %q, want %q", received, want)
+	}
+}
+
+func TestHistoryAppendsAndCompacts(t *testing.T) {
+	useTempAppFolder(t)
+	legacy := `[{"id":"old-1","url":"http://a"},{"id":"old-2","url":"http://b"}]`
+	if err := os.WriteFile(legacyHistoryFilePath(), []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := NewApp()
+	entries, err := a.GetHistory(HistoryFilter{})
+	if err != nil || len(entries) != 2 || entries[0].Id != "old-2" {
+		t.Fatalf("migrated history = %+v, %v", entries, err)
+	}
+	if _, err := os.Stat(legacyHistoryFilePath()); !os.IsNotExist(err) {
+		t.Errorf("old history file was not removed: %v", err)
+	}
+
+	for i := 2; i < 2*maxHistoryEntries; i++ {
+		if err := appendHistory(HistoryEntry{Id: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	contents, err := os.ReadFile(historyFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(contents), "\n"); lines != maxHistoryEntries {
+		t.Errorf("history file has %d lines after compaction, want %d", lines, maxHistoryEntries)
+	}
+	entries, err = a.GetHistory(HistoryFilter{Limit: 1})
+	if err != nil || len(entries) != 1 || entries[0].Id != strconv.Itoa(2*maxHistoryEntries-1) {
+		t.Errorf("newest entry = %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// baseContext is the parent of every request context: the Wails app context
+// when running the GUI, so in-flight requests stop on shutdown, or
+// context.Background for the CLI.
+func (a *App) baseContext() context.Context {
+	if a.ctx != nil {
+		return a.ctx
+	}
+	return context.Background()
+}
+
+// inflightRequest is the entry of a running request; its address tells a
+// send from a later one reusing the id.
+type inflightRequest struct {
+	cancel context.CancelFunc
+}
+
+// startRequest registers a cancellable context for an in-flight request.
+// An empty id is replaced by a new one. The returned done func must be
+// called once the request finishes.
+func (a *App) startRequest(id string) (string, context.Context, func()) {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	ctx, cancel := context.WithCancel(a.baseContext())
+
+	a.inflightMu.Lock()
+	if a.inflight == nil {
+		a.inflight = map[string]*inflightRequest{}
+	}
+	if prev, ok := a.inflight[id]; ok {
+		// Reusing an id supersedes the earlier send
+		prev.cancel()
+	}
+	entry := &inflightRequest{cancel: cancel}
+	a.inflight[id] = entry
+	a.inflightMu.Unlock()
+
+	done := func() {
+		a.inflightMu.Lock()
+		if a.inflight[id] == entry {
+			delete(a.inflight, id)
+		}
+		a.inflightMu.Unlock()
+		cancel()
+	}
+	return id, ctx, done
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// CancelRequest aborts the in-flight request started with the given id. The
+// pending SendRequest or ExecuteRequest call returns with status "Cancelled".
+func (a *App) CancelRequest(id string) error {
+	a.inflightMu.Lock()
+	entry, ok := a.inflight[id]
+	a.inflightMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no in-flight request with id: %s", id)
+	}
+	entry.cancel()
+	return nil
+}
+
+// GetInFlightRequests returns the ids of requests that are still running.
+func (a *App) GetInFlightRequests() []string {
+	a.inflightMu.Lock()
+	defer a.inflightMu.Unlock()
+
+	ids := make([]string, 0, len(a.inflight))
+	for id := range a.inflight {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// KeyValue is one row of a headers or query params table. Keys may repeat
+// to send several values.
+type KeyValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Enabled bool   `json:"enabled"`
+
+	// invalid holds saved text that is not a valid table. Such a row is the
+	// only one of its table; the text is written back as it was and sending
+	// the table fails.
+	invalid string
+}
+
+// UnmarshalJSON defaults a missing "enabled" to true, so rows written by
+// hand or by other tools are sent.
+func (kv *KeyValue) UnmarshalJSON(data []byte) error {
+	type plain KeyValue
+	row := plain{Enabled: true}
+	if err := json.Unmarshal(data, &row); err != nil {
+		return err
+	}
+	*kv = KeyValue(row)
+	return nil
+}
+
+// KeyValues is an ordered headers or query params table. It is stored and
+// sent to the frontend as a string, like before tables were ordered: a JSON
+// object of keys to values when that loses nothing, and a JSON array of
+// KeyValue otherwise (for repeated keys or disabled rows). It also loads a
+// bare JSON object or array. Object entries become enabled rows in
+// document order. Fields of this type are tagged ts_type:"string" so the
+// generated frontend bindings agree.
+type KeyValues []KeyValue
+
+func (k KeyValues) MarshalJSON() ([]byte, error) {
+	if err := k.parseError(); err != nil {
+		return json.Marshal(k[0].invalid)
+	}
+	if len(k) == 0 {
+		return json.Marshal("")
+	}
+	var text []byte
+	if k.isObject() {
+		var b bytes.Buffer
+		b.WriteByte('{')
+		for i, kv := range k {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(kv.Key)
+			value, _ := json.Marshal(kv.Value)
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(value)
+		}
+		b.WriteByte('}')
+		text = b.Bytes()
+	} else {
+		var err error
+		if text, err = json.Marshal([]KeyValue(k)); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(string(text))
+}
+
+// isObject reports whether k can be written as a JSON object: every row is
+// enabled and no key repeats.
+func (k KeyValues) isObject() bool {
+	seen := map[string]bool{}
+	for _, kv := range k {
+		if !kv.Enabled || seen[kv.Key] {
+			return false
+		}
+		seen[kv.Key] = true
+	}
+	return true
+}
+
+// parseError returns an error if k holds saved text that is not a valid
+// table.
+func (k KeyValues) parseError() error {
+	if len(k) == 1 && k[0].invalid != "" {
+		return fmt.Errorf("invalid JSON: %s", k[0].invalid)
+	}
+	return nil
+}
+
+func (k *KeyValues) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*k = nil
+	case len(data) > 0 && data[0] == '[':
+		var rows []KeyValue
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return err
+		}
+		*k = rows
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		rows, err := parseKeyValues(s)
+		if err != nil {
+			// Keep the text so that it is saved back unchanged and can be
+			// fixed; sending the table reports the error
+			rows = KeyValues{{invalid: s}}
+		}
+		*k = rows
+	default:
+		rows, err := parseKeyValueObject(data)
+		if err != nil {
+			return err
+		}
+		*k = rows
+	}
+	return nil
+}
+
+// parseKeyValues parses a table sent as a string: a JSON array of KeyValue
+// or a JSON object of keys to values. An empty string is an empty table.
+func parseKeyValues(s string) (KeyValues, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] == '[' {
+		var rows []KeyValue
+		err := json.Unmarshal([]byte(s), &rows)
+		return rows, err
+	}
+	return parseKeyValueObject([]byte(s))
+}
+
+// parseKeyValueObject converts a JSON object to enabled rows, keeping the
+// order of its keys. Non-string values are kept as their JSON text and
+// nulls are skipped, as for variables.
+func parseKeyValueObject(data []byte) (KeyValues, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, errors.New("expected a JSON object or array")
+	}
+	var rows KeyValues
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		row := KeyValue{Key: tok.(string), Enabled: true}
+		switch {
+		case bytes.Equal(raw, []byte("null")):
+			continue
+		case raw[0] == '"':
+			json.Unmarshal(raw, &row.Value)
+		default:
+			row.Value = string(raw)
+		}
+		rows = append(rows, row)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// resolved returns the enabled rows with placeholders substituted in keys
+// and values. Rows left without a key are dropped. Check parseError
+// first.
+func (k KeyValues) resolved(variables map[string]string) KeyValues {
+	var out KeyValues
+	for _, kv := range k {
+		if !kv.Enabled {
+			continue
+		}
+		kv.Key = strings.TrimSpace(replacePlaceholders(kv.Key, variables))
+		if kv.Key == "" {
+			continue
+		}
+		kv.Value = replacePlaceholders(kv.Value, variables)
+		out = append(out, kv)
+	}
+	return out
+}
+
+// has reports whether any row has the given header name.
+func (k KeyValues) has(name string) bool {
+	for _, kv := range k {
+		if strings.EqualFold(kv.Key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideHeaders returns k with every header named in rows replaced by
+// the values in rows.
+func (k KeyValues) overrideHeaders(rows KeyValues) KeyValues {
+	var out KeyValues
+	for _, kv := range k {
+		if !rows.has(kv.Key) {
+			out = append(out, kv)
+		}
+	}
+	return append(out, rows...)
+}
+
+// applyHeaders replaces the headers of h named in k with k's values, in
+// order.
+func (k KeyValues) applyHeaders(h http.Header) {
+	for _, kv := range k {
+		h.Del(kv.Key)
+	}
+	for _, kv := range k {
+		h.Add(kv.Key, kv.Value)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKeyValuesMissingEnabled(t *testing.T) {
+	want := KeyValues{{Key: "a", Value: "b", Enabled: true}}
+	for _, in := range []string{
+		`[{"key":"a","value":"b"}]`,
+		`"[{\"key\":\"a\",\"value\":\"b\"}]"`,
+	} {
+		var got KeyValues
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", in, got, want)
+		}
+		if resolved := got.resolved(nil); len(resolved) != 1 {
+			t.Errorf("%s: resolved to %+v, want the row sent", in, resolved)
+		}
+	}
+
+	var got KeyValues
+	if err := json.Unmarshal([]byte(`[{"key":"a","value":"b","enabled":false}]`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Enabled {
+		t.Error(`"enabled": false was ignored`)
+	}
+}
+
+func TestKeyValuesRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		`"{\"A\":\"1\",\"B\":\"2\"}"`,
+		`"[{\"key\":\"A\",\"value\":\"1\",\"enabled\":true},{\"key\":\"A\",\"value\":\"2\",\"enabled\":false}]"`,
+		`"{not json"`,
+	} {
+		var rows KeyValues
+		if err := json.Unmarshal([]byte(in), &rows); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		out, err := json.Marshal(rows)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if string(out) != in {
+			t.Errorf("round trip of %s gave %s", in, out)
+		}
+	}
+}
+
+func TestSendRejectsUnparsedRows(t *testing.T) {
+	useTempAppFolder(t)
+	var bad KeyValues
+	if err := json.Unmarshal([]byte(`"{not json"`), &bad); err != nil {
+		t.Fatal(err)
+	}
+	for name, r := range map[string]Request{
+		"headers":      {Method: "GET", URL: "http://127.0.0.1:1/", Headers: bad},
+		"query params": {Method: "GET", URL: "http://127.0.0.1:1/", QueryParams: bad},
+		"path params":  {Method: "GET", URL: "http://127.0.0.1:1/:id", PathParams: bad},
+	} {
+		resp, _ := NewApp().send(context.Background(), r, sendOptions{})
+		if resp.Status != "Configuration Error" {
+			t.Errorf("%s: status %q, want a configuration error", name, resp.Status)
+		}
+	}
+}
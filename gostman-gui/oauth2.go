@@ -0,0 +1,474 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth 2.0 grant types for OAuth2Config.GrantType.
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password"
+	GrantAuthorizationCode = "authorization_code"
+)
+
+// oauth2AuthorizeTimeout bounds how long the authorization code flow waits
+// for the user to finish in the browser.
+const oauth2AuthorizeTimeout = 5 * time.Minute
+
+// oauth2ExpirySkew refreshes tokens slightly before they expire so a
+// request is not sent with a token that lapses in flight.
+const oauth2ExpirySkew = 30 * time.Second
+
+// OAuth2Config configures AuthConfig of type "oauth2". The password grant
+// uses AuthConfig.Username and Password for the resource owner.
+type OAuth2Config struct {
+	GrantType    string `json:"grantType"`
+	TokenURL     string `json:"tokenUrl"`
+	AuthURL      string `json:"authUrl"`
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	Scope        string `json:"scope"`
+	// ClientAuth sends the client credentials as HTTP Basic ("header",
+	// the default) or as form fields ("body").
+	ClientAuth string `json:"clientAuth"`
+	// RedirectPort fixes the loopback redirect listener's port for
+	// providers that require an exact redirect URI; 0 picks a free port.
+	RedirectPort int `json:"redirectPort"`
+}
+
+// OAuth2Token is a cached access token. Expiry is RFC 3339, or "" if the
+// server did not say when the token expires.
+type OAuth2Token struct {
+	AccessToken  string `json:"accessToken"`
+	TokenType    string `json:"tokenType"`
+	RefreshToken string `json:"refreshToken"`
+	Expiry       string `json:"expiry"`
+	Scope        string `json:"scope"`
+}
+
+func (t OAuth2Token) valid(now time.Time) bool {
+	if t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry == "" {
+		return true
+	}
+	expiry, err := time.Parse(time.RFC3339, t.Expiry)
+	return err == nil && now.Add(oauth2ExpirySkew).Before(expiry)
+}
+
+// authorization returns the Authorization header value for the token.
+func (t OAuth2Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// tokenStore maps an environment id to its cached tokens, keyed by
+// oauth2CacheKey.
+type tokenStore map[string]map[string]OAuth2Token
+
+var (
+	// oauth2Locks serializes token acquisition per environment and cache
+	// key so concurrent sends share one token (and one browser prompt)
+	// instead of racing, while other tokens are fetched independently. The
+	// values are channels with room for one holder (see lockOAuth2).
+	oauth2Locks sync.Map
+	tokensMutex sync.Mutex
+)
+
+// lockOAuth2 takes the token lock for key, waiting until it is free or
+// ctx is done, and returns the func releasing it.
+func lockOAuth2(ctx context.Context, key string) (func(), error) {
+	v, _ := oauth2Locks.LoadOrStore(key, make(chan struct{}, 1))
+	lock := v.(chan struct{})
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// tokenClient returns the client for the token calls of a send made with
+// client. It shares the transport, so TLS and proxy settings apply, but
+// has no cookie jar and does not record redirects as the send's own.
+func tokenClient(client *http.Client) *http.Client {
+	return &http.Client{Transport: client.Transport, Timeout: client.Timeout}
+}
+
+func tokensFilePath() string {
+	return filepath.Join(appFolder, "oauth2_tokens.json")
+}
+
+func readTokens() (tokenStore, error) {
+	store := tokenStore{}
+	file, err := os.ReadFile(tokensFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &store); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tokens: %w", err)
+		}
+	}
+	return store, nil
+}
+
+// mutateTokens runs fn over the token store and writes it back.
+func mutateTokens(fn func(store tokenStore)) error {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+
+	store, err := readTokens()
+	if err != nil {
+		return err
+	}
+	fn(store)
+
+	if err := os.MkdirAll(appFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	encoded, err := json.MarshalIndent(store, "", " ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if err := os.WriteFile(tokensFilePath(), encoded, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// oauth2CacheKey identifies the token a config obtains: the same grant,
+// endpoint, client, scope and user share a token.
+func oauth2CacheKey(c AuthConfig) string {
+	o := c.OAuth2
+	sum := sha256.Sum256([]byte(strings.Join([]string{o.GrantType, o.TokenURL, o.ClientId, o.Scope, c.Username}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// oauth2Token returns a valid token for c, from the cache of environment
+// envId if possible, else by refreshing or acquiring a new one. New tokens
+// are cached. Token calls go through tokenClient(client).
+func (a *App) oauth2Token(ctx context.Context, client *http.Client, envId string, c AuthConfig) (OAuth2Token, error) {
+	if c.OAuth2 == nil {
+		return OAuth2Token{}, errors.New("OAuth 2.0 settings are missing")
+	}
+	if c.OAuth2.TokenURL == "" {
+		return OAuth2Token{}, errors.New("OAuth 2.0 token URL is required")
+	}
+
+	key := oauth2CacheKey(c)
+	unlock, err := lockOAuth2(ctx, envId+"/"+key)
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+	defer unlock()
+	client = tokenClient(client)
+
+	tokensMutex.Lock()
+	store, err := readTokens()
+	tokensMutex.Unlock()
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+	cached := store[envId][key]
+	if cached.valid(time.Now()) {
+		return cached, nil
+	}
+
+	var token OAuth2Token
+	if cached.RefreshToken != "" {
+		token, err = requestToken(ctx, client, c, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {cached.RefreshToken},
+		})
+		if err == nil && token.RefreshToken == "" {
+			// Servers may omit the refresh token when it is unchanged
+			token.RefreshToken = cached.RefreshToken
+		}
+	}
+	if cached.RefreshToken == "" || err != nil {
+		token, err = a.acquireToken(ctx, client, c)
+		if err != nil {
+			return OAuth2Token{}, err
+		}
+	}
+
+	if err := mutateTokens(func(store tokenStore) {
+		if store[envId] == nil {
+			store[envId] = map[string]OAuth2Token{}
+		}
+		store[envId][key] = token
+	}); err != nil {
+		return OAuth2Token{}, err
+	}
+	return token, nil
+}
+
+// acquireToken runs the configured grant from scratch.
+func (a *App) acquireToken(ctx context.Context, client *http.Client, c AuthConfig) (OAuth2Token, error) {
+	o := c.OAuth2
+	switch o.GrantType {
+	case GrantClientCredentials:
+		return requestToken(ctx, client, c, url.Values{"grant_type": {GrantClientCredentials}})
+	case GrantPassword:
+		return requestToken(ctx, client, c, url.Values{
+			"grant_type": {GrantPassword},
+			"username":   {c.Username},
+			"password":   {c.Password},
+		})
+	case GrantAuthorizationCode:
+		return a.authorizationCodeFlow(ctx, client, c)
+	default:
+		return OAuth2Token{}, fmt.Errorf("unsupported OAuth 2.0 grant type: %s", o.GrantType)
+	}
+}
+
+// requestToken posts form to the token endpoint, adding scope and client
+// authentication, and decodes the token response.
+func requestToken(ctx context.Context, client *http.Client, c AuthConfig, form url.Values) (OAuth2Token, error) {
+	o := c.OAuth2
+	if o.Scope != "" && form.Get("grant_type") != GrantAuthorizationCode {
+		form.Set("scope", o.Scope)
+	}
+	if o.ClientAuth == "body" {
+		form.Set("client_id", o.ClientId)
+		if o.ClientSecret != "" {
+			form.Set("client_secret", o.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("invalid token URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.ClientAuth != "body" {
+		req.SetBasicAuth(url.QueryEscape(o.ClientId), url.QueryEscape(o.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var tr struct {
+		AccessToken      string          `json:"access_token"`
+		TokenType        string          `json:"token_type"`
+		RefreshToken     string          `json:"refresh_token"`
+		ExpiresIn        json.RawMessage `json:"expires_in"`
+		Scope            string          `json:"scope"`
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tr); err != nil {
+		// Some providers still answer with a form-encoded body
+		values, formErr := url.ParseQuery(string(body))
+		if formErr != nil || values.Get("access_token") == "" && values.Get("error") == "" {
+			return OAuth2Token{}, fmt.Errorf("unexpected token response (%s)", resp.Status)
+		}
+		tr.AccessToken, tr.TokenType = values.Get("access_token"), values.Get("token_type")
+		tr.RefreshToken, tr.Scope = values.Get("refresh_token"), values.Get("scope")
+		tr.ExpiresIn = json.RawMessage(values.Get("expires_in"))
+		tr.Error, tr.ErrorDescription = values.Get("error"), values.Get("error_description")
+	}
+	if tr.Error != "" {
+		if tr.ErrorDescription != "" {
+			return OAuth2Token{}, fmt.Errorf("token request rejected: %s: %s", tr.Error, tr.ErrorDescription)
+		}
+		return OAuth2Token{}, fmt.Errorf("token request rejected: %s", tr.Error)
+	}
+	if resp.StatusCode/100 != 2 || tr.AccessToken == "" {
+		return OAuth2Token{}, fmt.Errorf("token request failed: %s", resp.Status)
+	}
+
+	token := OAuth2Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		Scope:        tr.Scope,
+	}
+	// expires_in is a number, but some servers send it as a string
+	if seconds, err := strconv.Atoi(strings.Trim(string(tr.ExpiresIn), `"`)); err == nil && seconds > 0 {
+		token.Expiry = time.Now().Add(time.Duration(seconds) * time.Second).UTC().Format(time.RFC3339)
+	}
+	return token, nil
+}
+
+// randomURLString returns n random bytes encoded as unpadded base64url.
+func randomURLString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// authorizationCodeFlow runs the authorization code grant with PKCE: it
+// listens on a loopback redirect URI, sends the user to the authorization
+// URL and exchanges the returned code for a token.
+func (a *App) authorizationCodeFlow(ctx context.Context, client *http.Client, c AuthConfig) (OAuth2Token, error) {
+	o := c.OAuth2
+	if o.AuthURL == "" {
+		return OAuth2Token{}, errors.New("OAuth 2.0 authorization URL is required")
+	}
+	if a.openURL == nil {
+		return OAuth2Token{}, errors.New("the authorization code grant needs a browser")
+	}
+
+	verifier, err := randomURLString(32)
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+	state, err := randomURLString(16)
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(o.RedirectPort)))
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("failed to start redirect listener: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("authorization response has an invalid state")
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = errors.New("authorization response has no code")
+		default:
+			res.code = q.Get("code")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if res.err != nil {
+			fmt.Fprint(w, "<h3>Gostman authorization failed.</h3><p>You can close this window.</p>")
+		} else {
+			fmt.Fprint(w, "<h3>Gostman authorization complete.</h3><p>You can close this window.</p>")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL, err := url.Parse(o.AuthURL)
+	if err != nil {
+		return OAuth2Token{}, fmt.Errorf("invalid authorization URL: %w", err)
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", o.ClientId)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	if o.Scope != "" {
+		q.Set("scope", o.Scope)
+	}
+	authURL.RawQuery = q.Encode()
+	a.openURL(authURL.String())
+
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return OAuth2Token{}, ctx.Err()
+	case <-time.After(oauth2AuthorizeTimeout):
+		return OAuth2Token{}, errors.New("timed out waiting for authorization in the browser")
+	}
+	if res.err != nil {
+		return OAuth2Token{}, res.err
+	}
+
+	return requestToken(ctx, client, c, url.Values{
+		"grant_type":    {GrantAuthorizationCode},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetOAuth2Token returns the token r's OAuth 2.0 auth (explicit or
+// inherited) would use, acquiring or refreshing it if needed. It backs the
+// "Get New Access Token" button.
+func (a *App) GetOAuth2Token(r Request) (OAuth2Token, error) {
+	data := getSavedData()
+	auth := effectiveAuth(&data, r)
+	if auth == nil || auth.Type != AuthOAuth2 {
+		return OAuth2Token{}, errors.New("request does not use OAuth 2.0")
+	}
+	scopes, err := a.variableScopes(data, r, nil)
+	if err != nil {
+		return OAuth2Token{}, err
+	}
+	envId := ""
+	if env := a.environmentFor(&data); env != nil {
+		envId = env.Id
+	}
+	var redirects []RedirectHop
+	client := newHTTPClient(data.settings().withOverrides(r.Settings), &data, envId, false, &redirects)
+	defer client.CloseIdleConnections()
+	return a.oauth2Token(a.baseContext(), client, envId, auth.substitute(scopes.merged()))
+}
+
+// ClearOAuth2Tokens forgets the cached tokens of the active environment so
+// the next request acquires new ones.
+func (a *App) ClearOAuth2Tokens() error {
+	data := getSavedData()
+	envId := ""
+	if env := a.environmentFor(&data); env != nil {
+		envId = env.Id
+	}
+	return mutateTokens(func(store tokenStore) {
+		delete(store, envId)
+	})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ProxyConfig routes outgoing requests through a proxy. Rules apply in
+// order, those of the active environment before global ones (empty
+// EnvironmentId); the first enabled rule whose Hosts match decides. A
+// request no rule matches uses the HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+// environment variables.
+type ProxyConfig struct {
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	EnvironmentId string `json:"environmentId"`
+	Enabled       bool   `json:"enabled"`
+	// URL is http://, https://, socks5:// or socks5h:// (resolve names on
+	// the proxy); a bare host:port is an HTTP proxy
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Hosts limits the rule to matching hosts; empty matches every host.
+	// Patterns are as for ClientCertificate.Host.
+	Hosts []string `json:"hosts"`
+	// Bypass lists hosts sent directly instead: host patterns or CIDR
+	// ranges such as 10.0.0.0/8
+	Bypass []string `json:"bypass"`
+}
+
+// proxyURL returns the parsed proxy URL with the credentials filled in.
+func (p ProxyConfig) proxyURL() (*url.URL, error) {
+	raw := strings.TrimSpace(p.URL)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("proxy URL has no host")
+	}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u, nil
+}
+
+// bypassMatches reports whether pattern (a host pattern or CIDR range)
+// covers host, which carries a port.
+func bypassMatches(pattern, host string) bool {
+	if _, network, err := net.ParseCIDR(strings.TrimSpace(pattern)); err == nil {
+		h, _, err := net.SplitHostPort(host)
+		if err != nil {
+			h = host
+		}
+		ip := net.ParseIP(h)
+		return ip != nil && network.Contains(ip)
+	}
+	return hostMatches(pattern, host)
+}
+
+// proxyRules returns the enabled rules that apply in environment envId, in
+// the order they are tried.
+func (data *SavedData) proxyRules(envId string) []ProxyConfig {
+	var envRules, globalRules []ProxyConfig
+	for _, p := range data.Proxies {
+		switch {
+		case !p.Enabled:
+		case p.EnvironmentId == "":
+			globalRules = append(globalRules, p)
+		case p.EnvironmentId == envId:
+			envRules = append(envRules, p)
+		}
+	}
+	return append(envRules, globalRules...)
+}
+
+// proxyFunc returns the http.Transport.Proxy func for the given rules.
+func proxyFunc(rules []ProxyConfig) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		host := canonicalHost(req.URL)
+		for _, p := range rules {
+			matched := len(p.Hosts) == 0
+			for _, pattern := range p.Hosts {
+				if hostMatches(pattern, host) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+			for _, pattern := range p.Bypass {
+				if bypassMatches(pattern, host) {
+					return nil, nil
+				}
+			}
+			return p.proxyURL()
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetProxies returns every proxy rule in the order they are tried.
+func (a *App) GetProxies() []ProxyConfig {
+	data := getSavedData()
+	result := make([]ProxyConfig, len(data.Proxies))
+	copy(result, data.Proxies)
+	return result
+}
+
+// SaveProxy creates a proxy rule (empty Id, appended last) or updates an
+// existing one.
+func (a *App) SaveProxy(p ProxyConfig) (ProxyConfig, error) {
+	if _, err := p.proxyURL(); err != nil {
+		return ProxyConfig{}, err
+	}
+	for _, pattern := range p.Bypass {
+		if strings.TrimSpace(pattern) == "" {
+			return ProxyConfig{}, errors.New("bypass entries must not be empty")
+		}
+	}
+
+	var saveErr error
+	err := a.mutateSavedData(func(data *SavedData) {
+		if p.EnvironmentId != "" && data.environment(p.EnvironmentId) == nil {
+			saveErr = fmt.Errorf("environment not found: %s", p.EnvironmentId)
+			return
+		}
+		if p.Id == "" {
+			p.Id = uuid.New().String()
+			data.Proxies = append(data.Proxies, p)
+			return
+		}
+		for i := range data.Proxies {
+			if data.Proxies[i].Id == p.Id {
+				data.Proxies[i] = p
+				return
+			}
+		}
+		saveErr = fmt.Errorf("proxy not found: %s", p.Id)
+	})
+	if err != nil {
+		return ProxyConfig{}, err
+	}
+	if saveErr != nil {
+		return ProxyConfig{}, saveErr
+	}
+	return p, nil
+}
+
+func (a *App) DeleteProxy(id string) error {
+	var notFound bool
+	err := a.mutateSavedData(func(data *SavedData) {
+		for i, p := range data.Proxies {
+			if p.Id == id {
+				data.Proxies = append(data.Proxies[:i], data.Proxies[i+1:]...)
+				return
+			}
+		}
+		notFound = true
+	})
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return fmt.Errorf("proxy not found: %s", id)
+	}
+	return nil
+}
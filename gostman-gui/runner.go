@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// RunProgressEvent is the runtime event carrying a RunProgress after
+	// each request of a collection run.
+	RunProgressEvent = "runner:progress"
+	// RunDoneEvent is the runtime event carrying the final RunSummary.
+	RunDoneEvent = "runner:done"
+)
+
+// RunOptions configures a collection run.
+type RunOptions struct {
+	// FolderId is the folder to run, with its subfolders; "" runs every
+	// saved request
+	FolderId string `json:"folderId"`
+	// Iterations is how many times the requests run; 0 means once, or once
+	// per row of DataFile
+	Iterations int `json:"iterations"`
+	// DelayMs is the pause between two requests
+	DelayMs int `json:"delayMs"`
+	// StopOnFailure ends the run after the first failed request
+	StopOnFailure bool `json:"stopOnFailure"`
+	// DataFile is a CSV or JSON file whose rows provide the "data"
+	// variables of successive iterations (see datafile.go). Iterations
+	// past the last row reuse it.
+	DataFile string `json:"dataFile"`
+}
+
+// RunResult is the outcome of one request in a run. A request fails when
+// it could not be sent, a test failed or the test script threw.
+type RunResult struct {
+	Iteration int    `json:"iteration"`
+	RequestId string `json:"requestId"`
+	Name      string `json:"name"`
+	Method    string `json:"method"`
+	// URL is the URL that was sent, with credentials redacted as in the
+	// history, or the request's unresolved URL if it was never sent
+	URL         string       `json:"url"`
+	Status      string       `json:"status"`
+	StatusCode  int          `json:"statusCode"`
+	DurationMs  float64      `json:"durationMs"`
+	Size        int64        `json:"size"`
+	Passed      bool         `json:"passed"`
+	Error       string       `json:"error"`
+	TestResults []TestResult `json:"testResults"`
+}
+
+// RunProgress reports a finished request of the run with RunId.
+type RunProgress struct {
+	RunId     string    `json:"runId"`
+	Completed int       `json:"completed"`
+	Total     int       `json:"total"`
+	Result    RunResult `json:"result"`
+}
+
+// RunSummary is the report of a finished run. Stopped is set when
+// StopOnFailure ended it early and Cancelled when it was cancelled.
+type RunSummary struct {
+	RunId       string  `json:"runId"`
+	FolderId    string  `json:"folderId"`
+	Iterations  int     `json:"iterations"`
+	Requests    int     `json:"requests"`
+	Passed      int     `json:"passed"`
+	Failed      int     `json:"failed"`
+	Tests       int     `json:"tests"`
+	TestsPassed int     `json:"testsPassed"`
+	TestsFailed int     `json:"testsFailed"`
+	DurationMs  float64 `json:"durationMs"`
+	// AvgResponseMs is the mean response time of the requests sent
+	AvgResponseMs float64     `json:"avgResponseMs"`
+	Stopped       bool        `json:"stopped"`
+	Cancelled     bool        `json:"cancelled"`
+	Results       []RunResult `json:"results"`
+	// DataRows totals the results per iteration when a data file is used
+	DataRows []DataRowResult `json:"dataRows"`
+}
+
+// DataRowResult is the outcome of the iteration run with one data file row.
+type DataRowResult struct {
+	Iteration   int               `json:"iteration"`
+	Data        map[string]string `json:"data"`
+	Passed      int               `json:"passed"`
+	Failed      int               `json:"failed"`
+	TestsPassed int               `json:"testsPassed"`
+	TestsFailed int               `json:"testsFailed"`
+}
+
+// sendFailed reports whether resp describes a local failure (bad
+// configuration, network error, cancellation) rather than a response from
+// the server.
+func sendFailed(resp ResponseMsg) bool {
+	return resp.Status == "Error" || resp.Status == "Configuration Error" || resp.Status == "Cancelled"
+}
+
+// runOrder returns the requests of folderId in run order: the folder's own
+// requests by Order, then each subfolder's in turn. An empty folderId
+// starts from the top level, covering every request.
+func (data *SavedData) runOrder(folderId string) []Request {
+	seen := map[string]bool{}
+	var visit func(id string) []Request
+	visit = func(id string) []Request {
+		seen[id] = true
+		var requests []Request
+		for _, r := range data.Requests {
+			if r.FolderId == id || (id == "" && data.folder(r.FolderId) == nil) {
+				requests = append(requests, r)
+			}
+		}
+		sort.SliceStable(requests, func(i, j int) bool { return requests[i].Order < requests[j].Order })
+
+		var children []Folder
+		for _, f := range data.Folders {
+			if f.ParentId == id && !seen[f.Id] {
+				children = append(children, f)
+			}
+		}
+		sort.SliceStable(children, func(i, j int) bool { return children[i].Order < children[j].Order })
+		for _, f := range children {
+			requests = append(requests, visit(f.Id)...)
+		}
+		return requests
+	}
+	return visit(folderId)
+}
+
+// runRequests sends requests in order for every iteration, each with the
+// next of rows (loaded from opts.DataFile) as its data variables. The
+// requests share their pm.variables, so scripts can chain values from one
+// to the next. onResult, if set, is called after each request with its
+// response.
+func (a *App) runRequests(ctx context.Context, runId string, requests []Request, opts RunOptions, rows []map[string]string, onResult func(RunResult, ResponseMsg)) RunSummary {
+	iterations := opts.Iterations
+	if iterations == 0 {
+		iterations = max(len(rows), 1)
+	}
+	summary := RunSummary{RunId: runId, FolderId: opts.FolderId, Iterations: iterations}
+	start := time.Now()
+	locals := map[string]string{}
+	total := iterations * len(requests)
+	var responseMs float64
+
+run:
+	for it := 1; it <= iterations; it++ {
+		var row map[string]string
+		var rowResult *DataRowResult
+		if len(rows) > 0 {
+			row = rows[min(it, len(rows))-1]
+			summary.DataRows = append(summary.DataRows, DataRowResult{Iteration: it, Data: row})
+			rowResult = &summary.DataRows[len(summary.DataRows)-1]
+		}
+		for _, r := range requests {
+			if len(summary.Results) > 0 && opts.DelayMs > 0 {
+				select {
+				case <-time.After(time.Duration(opts.DelayMs) * time.Millisecond):
+				case <-ctx.Done():
+				}
+			}
+			if ctx.Err() != nil {
+				summary.Cancelled = true
+				break run
+			}
+
+			resp, sent := a.sendRecorded(ctx, r, sendOptions{locals: locals, row: row})
+			result := RunResult{
+				Iteration:   it,
+				RequestId:   r.Id,
+				Name:        r.Name,
+				Method:      r.Method,
+				URL:         r.URL,
+				Status:      resp.Status,
+				StatusCode:  statusCodeOf(resp.Status),
+				Size:        resp.Size,
+				Passed:      true,
+				TestResults: resp.TestResults,
+			}
+			if sent != nil {
+				result.URL = sent.url
+			}
+			if resp.Timing != nil {
+				result.DurationMs = resp.Timing.Total
+				responseMs += resp.Timing.Total
+			}
+			switch {
+			case sendFailed(resp):
+				result.Passed, result.Error = false, resp.Body
+			case resp.ScriptError != "":
+				result.Passed, result.Error = false, "Test script error: "+resp.ScriptError
+			}
+			for _, t := range resp.TestResults {
+				summary.Tests++
+				if t.Passed {
+					summary.TestsPassed++
+				} else {
+					summary.TestsFailed++
+					result.Passed = false
+				}
+			}
+
+			summary.Results = append(summary.Results, result)
+			summary.Requests++
+			if result.Passed {
+				summary.Passed++
+			} else {
+				summary.Failed++
+			}
+			if rowResult != nil {
+				rowResult.add(result)
+			}
+			a.emit(RunProgressEvent, RunProgress{RunId: runId, Completed: len(summary.Results), Total: total, Result: result})
+			if onResult != nil {
+				onResult(result, resp)
+			}
+
+			if resp.Status == "Cancelled" {
+				summary.Cancelled = true
+				break run
+			}
+			if !result.Passed && opts.StopOnFailure {
+				summary.Stopped = true
+				break run
+			}
+		}
+	}
+
+	summary.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	if summary.Requests > 0 {
+		summary.AvgResponseMs = responseMs / float64(summary.Requests)
+	}
+	return summary
+}
+
+// add counts result in the row's totals.
+func (d *DataRowResult) add(result RunResult) {
+	if result.Passed {
+		d.Passed++
+	} else {
+		d.Failed++
+	}
+	for _, t := range result.TestResults {
+		if t.Passed {
+			d.TestsPassed++
+		} else {
+			d.TestsFailed++
+		}
+	}
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// RunCollection runs the requests of a folder in order, like ExecuteRequest
+// would send them one after the other, once per iteration or data file row,
+// and returns the report. Each request
+// is followed by a "runner:progress" event and the run by a "runner:done"
+// event carrying the summary. runId works with CancelRequest to stop the
+// run; pass "" to have one generated.
+func (a *App) RunCollection(runId string, opts RunOptions) (RunSummary, error) {
+	data := getSavedData()
+	if opts.FolderId != "" && data.folder(opts.FolderId) == nil {
+		return RunSummary{}, fmt.Errorf("folder not found: %s", opts.FolderId)
+	}
+	if opts.Iterations < 0 || opts.DelayMs < 0 {
+		return RunSummary{}, errors.New("iterations and delay must not be negative")
+	}
+	requests := data.runOrder(opts.FolderId)
+	if len(requests) == 0 {
+		return RunSummary{}, errors.New("no requests to run")
+	}
+	var rows []map[string]string
+	if opts.DataFile != "" {
+		var err error
+		if rows, err = loadDataFile(opts.DataFile); err != nil {
+			return RunSummary{}, err
+		}
+	}
+
+	runId, ctx, done := a.startRequest(runId)
+	defer done()
+	summary := a.runRequests(ctx, runId, requests, opts, rows, nil)
+	a.emit(RunDoneEvent, summary)
+	return summary, nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunResultReportsSentURL(t *testing.T) {
+	useTempAppFolder(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	a := NewApp()
+	if err := a.SaveGlobals(`{"base":"` + srv.URL + `","user":"ann"}`); err != nil {
+		t.Fatal(err)
+	}
+	r := Request{Method: "GET", URL: "{{base}}/a?u={{user}}", QueryParams: KeyValues{{Key: "page", Value: "2", Enabled: true}}}
+	summary := a.runRequests(context.Background(), "run", []Request{r}, RunOptions{Iterations: 1}, nil, nil)
+	if len(summary.Results) != 1 {
+		t.Fatalf("got %d results", len(summary.Results))
+	}
+	if got, want := summary.Results[0].URL, srv.URL+"/a?u=ann&page=2"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// scriptTimeout bounds how long a pre-request or test script may run.
+const scriptTimeout = 10 * time.Second
+
+// TestResult is the outcome of one pm.test call in a test script.
+type TestResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error"`
+}
+
+// scriptResponse is the response a test script sees as pm.response.
+type scriptResponse struct {
+	code    int
+	status  string
+	headers []HeaderEntry
+	body    string
+	timeMs  float64
+	size    int64
+}
+
+// scriptEnv is shared by the scripts of one send. They read the variable
+// scopes and write to the local, environment and global ones; changes to
+// the latter two are saved by persist.
+type scriptEnv struct {
+	scopes variableScopes
+	// hasEnvironment is false when no environment is active, so
+	// pm.environment cannot be written
+	hasEnvironment bool
+	// changes maps a persisted scope to its set (non-nil) and unset (nil)
+	// keys
+	changes map[string]map[string]*string
+	tests   []TestResult
+	logs    []string
+}
+
+func newScriptEnv(scopes variableScopes, hasEnvironment bool) *scriptEnv {
+	return &scriptEnv{scopes: scopes, hasEnvironment: hasEnvironment, changes: map[string]map[string]*string{}}
+}
+
+// scope returns the variables of the named scope, or nil if it is missing.
+func (s *scriptEnv) scope(name string) map[string]string {
+	for _, sc := range s.scopes {
+		if sc.name == name {
+			return sc.vars
+		}
+	}
+	return nil
+}
+
+// get implements pm.<scope>.get. The local scope (pm.variables) resolves
+// through every scope, like a placeholder.
+func (s *scriptEnv) get(scope, key string) (string, bool) {
+	if scope == ScopeLocal {
+		value, _, ok := s.scopes.lookup(key)
+		return value, ok
+	}
+	value, ok := s.scope(scope)[key]
+	return value, ok
+}
+
+// set implements pm.<scope>.set and pm.<scope>.unset (value nil).
+func (s *scriptEnv) set(scope, key string, value *string) error {
+	if scope == ScopeEnvironment && !s.hasEnvironment {
+		return errors.New("no environment is active")
+	}
+	vars := s.scope(scope)
+	if vars == nil {
+		return fmt.Errorf("unknown variable scope: %s", scope)
+	}
+	if value != nil {
+		vars[key] = *value
+	} else {
+		delete(vars, key)
+	}
+	if scope == ScopeEnvironment || scope == ScopeGlobal {
+		if s.changes[scope] == nil {
+			s.changes[scope] = map[string]*string{}
+		}
+		s.changes[scope][key] = value
+	}
+	return nil
+}
+
+// run executes src with the pm API bound. resp is nil for pre-request
+// scripts. Scripts are stopped after scriptTimeout or when ctx is done.
+func (s *scriptEnv) run(ctx context.Context, name, src string, r Request, resp *scriptResponse) error {
+	vm := goja.New()
+	timer := time.AfterFunc(scriptTimeout, func() {
+		vm.Interrupt(fmt.Sprintf("script timed out after %s", scriptTimeout))
+	})
+	defer timer.Stop()
+	stop := context.AfterFunc(ctx, func() { vm.Interrupt("request cancelled") })
+	defer stop()
+
+	host := map[string]any{
+		"get": func(scope, key string) goja.Value {
+			if value, ok := s.get(scope, key); ok {
+				return vm.ToValue(value)
+			}
+			return goja.Undefined()
+		},
+		"has": func(scope, key string) bool {
+			_, ok := s.get(scope, key)
+			return ok
+		},
+		"set": func(scope, key, value string) error {
+			return s.set(scope, key, &value)
+		},
+		"unset": func(scope, key string) error {
+			return s.set(scope, key, nil)
+		},
+		"result": func(name string, passed bool, message string) {
+			s.tests = append(s.tests, TestResult{Name: name, Passed: passed, Error: message})
+		},
+		"log": func(line string) {
+			s.logs = append(s.logs, line)
+		},
+	}
+
+	var requestHeaders []any
+	for _, h := range r.Headers {
+		if h.Enabled {
+			requestHeaders = append(requestHeaders, map[string]any{"key": h.Key, "value": h.Value})
+		}
+	}
+	request := map[string]any{
+		"url":     r.URL,
+		"method":  r.Method,
+		"body":    r.Body,
+		"headers": requestHeaders,
+	}
+	var response any
+	if resp != nil {
+		var headers []any
+		for _, h := range resp.headers {
+			headers = append(headers, map[string]any{"key": h.Key, "value": h.Value})
+		}
+		response = map[string]any{
+			"code":         resp.code,
+			"status":       resp.status,
+			"headers":      headers,
+			"body":         resp.body,
+			"responseTime": resp.timeMs,
+			"size":         resp.size,
+		}
+	}
+
+	if _, err := vm.RunScript("prelude.js", scriptPrelude); err != nil {
+		return err
+	}
+	setup, _ := goja.AssertFunction(vm.Get("__setup"))
+	if _, err := setup(goja.Undefined(), vm.ToValue(host), vm.ToValue(request), vm.ToValue(response)); err != nil {
+		return err
+	}
+	_, err := vm.RunScript(name, src)
+	return err
+}
+
+// persist saves the environment and global variables the scripts changed.
+// Only the changed keys are written, so edits made meanwhile are kept.
+func (s *scriptEnv) persist(a *App) {
+	if len(s.changes) == 0 {
+		return
+	}
+	err := a.mutateSavedData(func(data *SavedData) {
+		if env := a.environmentFor(data); env != nil {
+			env.Variables = applyVariableChanges(env.Variables, s.changes[ScopeEnvironment])
+		}
+		data.Variables = applyVariableChanges(data.Variables, s.changes[ScopeGlobal])
+	})
+	if err != nil {
+		log.Printf("Error saving script variables: %v", err)
+	}
+}
+
+// applyVariableChanges applies set and unset keys to a JSON object of
+// variables. Unparseable variables are left as they are.
+func applyVariableChanges(variablesJSON string, changes map[string]*string) string {
+	if len(changes) == 0 {
+		return variablesJSON
+	}
+	vars := map[string]any{}
+	if strings.TrimSpace(variablesJSON) != "" {
+		if err := json.Unmarshal([]byte(variablesJSON), &vars); err != nil {
+			log.Printf("Error saving script variables: %v", err)
+			return variablesJSON
+		}
+	}
+	for key, value := range changes {
+		if value != nil {
+			vars[key] = *value
+		} else {
+			delete(vars, key)
+		}
+	}
+	encoded, err := json.Marshal(vars)
+	if err != nil {
+		return variablesJSON
+	}
+	return string(encoded)
+}
+
+// scriptError formats an error from run for the user, without the JS stack.
+func scriptError(err error) string {
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return exception.Value().String()
+	}
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return fmt.Sprint(interrupted.Value())
+	}
+	return err.Error()
+}
+
+// scriptPrelude defines __setup, which builds the pm and console globals
+// around the host functions of scriptEnv.run. pm.expect is a subset of the
+// Chai BDD assertions used by Postman scripts.
+const scriptPrelude = `
+function __setup(host, request, response) {
+	function str(v) {
+		return typeof v === 'object' && v !== null ? JSON.stringify(v) : String(v);
+	}
+	function show(v) {
+		if (typeof v === 'string') return JSON.stringify(v);
+		if (typeof v === 'object' && v !== null) {
+			try { return JSON.stringify(v); } catch (e) {}
+		}
+		return String(v);
+	}
+	function deepEqual(a, b) {
+		if (a === b) return true;
+		if (typeof a !== 'object' || typeof b !== 'object' || a === null || b === null) return false;
+		if (Array.isArray(a) !== Array.isArray(b)) return false;
+		var ka = Object.keys(a), kb = Object.keys(b);
+		if (ka.length !== kb.length) return false;
+		for (var i = 0; i < ka.length; i++) {
+			if (!Object.prototype.hasOwnProperty.call(b, ka[i]) || !deepEqual(a[ka[i]], b[ka[i]])) return false;
+		}
+		return true;
+	}
+	function typeOf(v) {
+		if (v === null) return 'null';
+		if (Array.isArray(v)) return 'array';
+		return typeof v;
+	}
+
+	function AssertionError(message) {
+		this.name = 'AssertionError';
+		this.message = message;
+	}
+	AssertionError.prototype = Object.create(Error.prototype);
+	AssertionError.prototype.toString = function () { return this.name + ': ' + this.message; };
+
+	function Assertion(actual, negate) {
+		this.actual = actual;
+		this.negate = negate;
+	}
+	// assert fails unless ok (or !ok when negated); {not} in message
+	// becomes "not " when negated
+	Assertion.prototype.assert = function (ok, message) {
+		if (!ok !== this.negate) {
+			throw new AssertionError(message.replace('{not}', this.negate ? 'not ' : ''));
+		}
+		return this;
+	};
+	['to', 'be', 'been', 'is', 'that', 'which', 'and', 'has', 'have', 'with', 'at', 'of', 'same', 'deep'].forEach(function (word) {
+		Object.defineProperty(Assertion.prototype, word, { get: function () { return this; } });
+	});
+	Object.defineProperty(Assertion.prototype, 'not', {
+		get: function () { return new Assertion(this.actual, !this.negate); }
+	});
+	var flags = {
+		ok: function (a) { return [!!a, 'expected ' + show(a) + ' to {not}be truthy']; },
+		true: function (a) { return [a === true, 'expected ' + show(a) + ' to {not}be true']; },
+		false: function (a) { return [a === false, 'expected ' + show(a) + ' to {not}be false']; },
+		null: function (a) { return [a === null, 'expected ' + show(a) + ' to {not}be null']; },
+		undefined: function (a) { return [a === undefined, 'expected ' + show(a) + ' to {not}be undefined']; },
+		exist: function (a) { return [a !== null && a !== undefined, 'expected ' + show(a) + ' to {not}exist']; },
+		empty: function (a) {
+			var n = typeof a === 'string' || Array.isArray(a) ? a.length : Object.keys(a || {}).length;
+			return [n === 0, 'expected ' + show(a) + ' to {not}be empty'];
+		}
+	};
+	Object.keys(flags).forEach(function (name) {
+		Object.defineProperty(Assertion.prototype, name, {
+			get: function () {
+				var r = flags[name](this.actual);
+				return this.assert(r[0], r[1]);
+			}
+		});
+	});
+	function method(names, fn) {
+		names.forEach(function (name) { Assertion.prototype[name] = fn; });
+	}
+	method(['equal', 'equals', 'eq'], function (v) {
+		return this.assert(this.actual === v, 'expected ' + show(this.actual) + ' to {not}equal ' + show(v));
+	});
+	method(['eql'], function (v) {
+		return this.assert(deepEqual(this.actual, v), 'expected ' + show(this.actual) + ' to {not}deeply equal ' + show(v));
+	});
+	method(['above', 'gt', 'greaterThan'], function (n) {
+		return this.assert(this.actual > n, 'expected ' + show(this.actual) + ' to {not}be above ' + show(n));
+	});
+	method(['least', 'gte'], function (n) {
+		return this.assert(this.actual >= n, 'expected ' + show(this.actual) + ' to {not}be at least ' + show(n));
+	});
+	method(['below', 'lt', 'lessThan'], function (n) {
+		return this.assert(this.actual < n, 'expected ' + show(this.actual) + ' to {not}be below ' + show(n));
+	});
+	method(['most', 'lte'], function (n) {
+		return this.assert(this.actual <= n, 'expected ' + show(this.actual) + ' to {not}be at most ' + show(n));
+	});
+	method(['include', 'includes', 'contain', 'contains'], function (v) {
+		var a = this.actual, ok = false;
+		if (typeof a === 'string') {
+			ok = a.indexOf(v) >= 0;
+		} else if (Array.isArray(a)) {
+			ok = a.some(function (item) { return deepEqual(item, v); });
+		} else if (typeof a === 'object' && a !== null && typeof v === 'object' && v !== null) {
+			ok = Object.keys(v).every(function (k) { return deepEqual(a[k], v[k]); });
+		}
+		return this.assert(ok, 'expected ' + show(a) + ' to {not}include ' + show(v));
+	});
+	method(['property'], function (name, value) {
+		var a = this.actual;
+		var has = a !== null && a !== undefined && name in Object(a);
+		if (arguments.length < 2) {
+			return this.assert(has, 'expected ' + show(a) + ' to {not}have property ' + show(name));
+		}
+		return this.assert(has && deepEqual(a[name], value),
+			'expected ' + show(a) + ' to {not}have property ' + show(name) + ' of ' + show(value));
+	});
+	method(['lengthOf'], function (n) {
+		var len = this.actual === null || this.actual === undefined ? undefined : this.actual.length;
+		return this.assert(len === n, 'expected ' + show(this.actual) + ' to {not}have length ' + n + ' but got ' + len);
+	});
+	method(['oneOf'], function (list) {
+		var a = this.actual;
+		return this.assert(list.some(function (item) { return deepEqual(item, a); }),
+			'expected ' + show(a) + ' to {not}be one of ' + show(list));
+	});
+	method(['a', 'an'], function (type) {
+		var t = typeOf(this.actual);
+		return this.assert(t === String(type).toLowerCase(), 'expected ' + show(this.actual) + ' to {not}be a ' + type + ' but got ' + t);
+	});
+	method(['match'], function (re) {
+		return this.assert(re.test(String(this.actual)), 'expected ' + show(this.actual) + ' to {not}match ' + re);
+	});
+
+	function expect(actual) {
+		return new Assertion(actual, false);
+	}
+
+	function scope(name) {
+		return {
+			get: function (key) { return host.get(name, String(key)); },
+			has: function (key) { return host.has(name, String(key)); },
+			set: function (key, value) { host.set(name, String(key), str(value)); },
+			unset: function (key) { host.unset(name, String(key)); }
+		};
+	}
+	function headerList(list) {
+		list = list || [];
+		return {
+			get: function (name) {
+				name = String(name).toLowerCase();
+				for (var i = 0; i < list.length; i++) {
+					if (list[i].key.toLowerCase() === name) return list[i].value;
+				}
+				return undefined;
+			},
+			has: function (name) { return this.get(name) !== undefined; },
+			all: function () { return list.map(function (h) { return { key: h.key, value: h.value }; }); }
+		};
+	}
+
+	var pm = {
+		variables: scope('` + ScopeLocal + `'),
+		environment: scope('` + ScopeEnvironment + `'),
+		globals: scope('` + ScopeGlobal + `'),
+		iterationData: {
+			get: function (key) { return host.get('` + ScopeData + `', String(key)); },
+			has: function (key) { return host.has('` + ScopeData + `', String(key)); }
+		},
+		request: {
+			url: request.url,
+			method: request.method,
+			body: request.body,
+			headers: headerList(request.headers)
+		},
+		test: function (name, fn) {
+			try {
+				fn();
+				host.result(String(name), true, '');
+			} catch (e) {
+				host.result(String(name), false, e && e.message !== undefined ? String(e.message) : String(e));
+			}
+		},
+		expect: expect
+	};
+
+	if (response) {
+		var headers = headerList(response.headers);
+		pm.response = {
+			code: response.code,
+			status: response.status,
+			headers: headers,
+			responseTime: response.responseTime,
+			size: response.size,
+			text: function () { return response.body; },
+			json: function () { return JSON.parse(response.body); },
+			to: {
+				have: {
+					status: function (code) {
+						if (typeof code === 'number' ? response.code !== code : response.status.indexOf(code) < 0) {
+							throw new AssertionError('expected response to have status ' + show(code) + ' but got ' + response.code);
+						}
+					},
+					header: function (name, value) {
+						var actual = headers.get(name);
+						if (actual === undefined) {
+							throw new AssertionError('expected response to have header ' + show(name));
+						}
+						if (arguments.length > 1 && actual !== value) {
+							throw new AssertionError('expected header ' + show(name) + ' to be ' + show(value) + ' but got ' + show(actual));
+						}
+					}
+				},
+				be: {}
+			}
+		};
+		Object.defineProperty(pm.response.to.be, 'ok', {
+			get: function () {
+				if (response.code < 200 || response.code > 299) {
+					throw new AssertionError('expected response to be ok but got ' + response.code);
+				}
+			}
+		});
+	}
+
+	function log() {
+		host.log(Array.prototype.map.call(arguments, function (v) {
+			return typeof v === 'string' ? v : show(v);
+		}).join(' '));
+	}
+	globalThis.pm = pm;
+	globalThis.console = { log: log, info: log, warn: log, error: log };
+}
+`
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HTTP version preferences for Settings.HTTPVersion.
+const (
+	HTTPVersionAuto  = "auto"  // negotiate via ALPN, preferring HTTP/2
+	HTTPVersionHTTP1 = "http1" // only speak HTTP/1.1
+	HTTPVersionHTTP2 = "http2" // require HTTP/2 over TLS; fail otherwise
+)
+
+// Settings are the global HTTP client settings used by SendRequest.
+type Settings struct {
+	// TimeoutMs bounds the whole exchange, including reading the body,
+	// except for downloads and bodies sent from files, where it only
+	// bounds connecting and waiting for the response headers. 0 disables
+	// the timeout.
+	TimeoutMs       int  `json:"timeoutMs"`
+	FollowRedirects bool `json:"followRedirects"`
+	// MaxRedirects is the number of redirects followed before the last
+	// redirect response is returned as-is.
+	MaxRedirects       int    `json:"maxRedirects"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+	HTTPVersion        string `json:"httpVersion"`
+	// DisableDecompression returns bodies as received instead of decoding
+	// their Content-Encoding, and stops sending a default Accept-Encoding.
+	DisableDecompression bool `json:"disableDecompression"`
+}
+
+// RequestSettings override Settings for a single request. Nil fields
+// inherit the global value.
+type RequestSettings struct {
+	TimeoutMs            *int    `json:"timeoutMs"`
+	FollowRedirects      *bool   `json:"followRedirects"`
+	MaxRedirects         *int    `json:"maxRedirects"`
+	InsecureSkipVerify   *bool   `json:"insecureSkipVerify"`
+	HTTPVersion          *string `json:"httpVersion"`
+	DisableDecompression *bool   `json:"disableDecompression"`
+}
+
+func defaultSettings() Settings {
+	return Settings{
+		TimeoutMs:       30000,
+		FollowRedirects: true,
+		MaxRedirects:    10,
+		HTTPVersion:     HTTPVersionAuto,
+	}
+}
+
+// settings returns the persisted global settings, or the defaults if none
+// were ever saved.
+func (data *SavedData) settings() Settings {
+	if data.Settings == nil {
+		return defaultSettings()
+	}
+	return *data.Settings
+}
+
+// withOverrides returns s with every non-nil field of o applied.
+func (s Settings) withOverrides(o *RequestSettings) Settings {
+	if o == nil {
+		return s
+	}
+	if o.TimeoutMs != nil {
+		s.TimeoutMs = *o.TimeoutMs
+	}
+	if o.FollowRedirects != nil {
+		s.FollowRedirects = *o.FollowRedirects
+	}
+	if o.MaxRedirects != nil {
+		s.MaxRedirects = *o.MaxRedirects
+	}
+	if o.InsecureSkipVerify != nil {
+		s.InsecureSkipVerify = *o.InsecureSkipVerify
+	}
+	if o.HTTPVersion != nil {
+		s.HTTPVersion = *o.HTTPVersion
+	}
+	if o.DisableDecompression != nil {
+		s.DisableDecompression = *o.DisableDecompression
+	}
+	return s
+}
+
+func (s Settings) validate() error {
+	if s.TimeoutMs < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if s.MaxRedirects < 0 {
+		return errors.New("max redirects cannot be negative")
+	}
+	switch s.HTTPVersion {
+	case "", HTTPVersionAuto, HTTPVersionHTTP1, HTTPVersionHTTP2:
+		return nil
+	default:
+		return fmt.Errorf("unknown HTTP version: %s", s.HTTPVersion)
+	}
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+func (a *App) GetSettings() Settings {
+	data := getSavedData()
+	return data.settings()
+}
+
+func (a *App) SaveSettings(s Settings) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	return a.mutateSavedData(func(data *SavedData) {
+		data.Settings = &s
+	})
+}
+
+// ResetSettings restores the default client settings.
+func (a *App) ResetSettings() error {
+	return a.mutateSavedData(func(data *SavedData) {
+		data.Settings = nil
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignV4 checks signV4 against cases of the AWS Signature Version 4
+// test suite, which all sign as of 20150830T123600Z with these credentials.
+func TestSignV4(t *testing.T) {
+	creds := AWSConfig{
+		AccessKeyId:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	const credential = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		body    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:   "get-vanilla",
+			method: "GET",
+			url:    "https://example.amazonaws.com/",
+			want:   "SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: "GET",
+			url:    "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want:   "SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "post-vanilla",
+			method: "POST",
+			url:    "https://example.amazonaws.com/",
+			want:   "SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:    "post-x-www-form-urlencoded",
+			method:  "POST",
+			url:     "https://example.amazonaws.com/",
+			body:    "Param1=value1",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			want:    "SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body == "" {
+				req.Body, req.GetBody = http.NoBody, nil
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if err := signV4(req, creds, now); err != nil {
+				t.Fatalf("signV4: %v", err)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+			if got, want := req.Header.Get("Authorization"), credential+tt.want; got != want {
+				t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+			}
+		})
+	}
+}
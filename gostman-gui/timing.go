@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing breaks a request's duration into sequential phases, in
+// milliseconds, so the response panel can draw a waterfall. Phases that did
+// not happen (e.g. DNS and Connect on a reused connection, TLS over plain
+// HTTP) are 0. When redirects were followed, the phases describe the final
+// hop while Total covers the whole exchange.
+type Timing struct {
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	TLS     float64 `json:"tls"`
+	// TTFB is the server wait: request fully written to first response byte.
+	TTFB     float64 `json:"ttfb"`
+	Download float64 `json:"download"`
+	Total    float64 `json:"total"`
+	// ReusedConnection is set when a keep-alive connection was reused.
+	ReusedConnection bool `json:"reusedConnection"`
+}
+
+// requestTrace collects httptrace timestamps for one request. Callbacks may
+// run on different goroutines, so fields are guarded by mu.
+type requestTrace struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+
+	remoteAddr string
+	reused     bool
+}
+
+func newRequestTrace() *requestTrace {
+	return &requestTrace{start: time.Now()}
+}
+
+func (t *requestTrace) set(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
+
+// clientTrace returns the hooks to attach with httptrace.WithClientTrace.
+// Each hop of a redirect chain overwrites the previous hop's timestamps.
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			t.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			// With several addresses, keep the first attempt's start
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone:       func(string, string, error) { t.set(&t.connectDone) },
+		TLSHandshakeStart: func() { t.set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.set(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte) },
+	}
+}
+
+// timing summarizes the trace, taking end as the moment the response body
+// was fully read.
+func (t *requestTrace) timing(end time.Time) *Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &Timing{
+		DNS:              phaseMs(t.dnsStart, t.dnsDone),
+		Connect:          phaseMs(t.connectStart, t.connectDone),
+		TLS:              phaseMs(t.tlsStart, t.tlsDone),
+		TTFB:             phaseMs(t.wroteRequest, t.firstByte),
+		Download:         phaseMs(t.firstByte, end),
+		Total:            phaseMs(t.start, end),
+		ReusedConnection: t.reused,
+	}
+}
+
+// remote returns the address of the connection the final response came from.
+func (t *requestTrace) remote() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.remoteAddr
+}
+
+// phaseMs returns end-start in milliseconds, or 0 if either is unset.
+func phaseMs(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return float64(end.Sub(start).Microseconds()) / 1000
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// pathParamRe matches a :name path variable at the start of a segment.
+var pathParamRe = regexp.MustCompile(`/:([A-Za-z_]\w*)`)
+
+// buildURL returns the URL sent for rawURL: https:// is added when there is
+// no scheme, :name path variables found in pathParams are replaced by
+// their escaped values, and params are merged into the query. The parts of
+// rawURL that are not changed keep their encoding byte for byte, so signed
+// URLs survive. pathParams and params must be resolved (see
+// KeyValues.resolved).
+func buildURL(rawURL string, params, pathParams KeyValues) (string, error) {
+	// Only a scheme before the query or fragment counts; "://" may appear
+	// in a query value such as a redirect URL
+	beforeQuery := rawURL
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		beforeQuery = rawURL[:i]
+	}
+	if !strings.Contains(beforeQuery, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	// Split into scheme://authority, path, query and fragment by hand;
+	// url.URL.String would re-encode them
+	rest, fragment, hasFragment := strings.Cut(rawURL, "#")
+	rest, query, hasQuery := strings.Cut(rest, "?")
+	authorityEnd := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		authorityEnd = i + len("://")
+		if j := strings.Index(rest[authorityEnd:], "/"); j >= 0 {
+			authorityEnd += j
+		} else {
+			authorityEnd = len(rest)
+		}
+	}
+	prefix, path := rest[:authorityEnd], rest[authorityEnd:]
+
+	if len(pathParams) > 0 {
+		values := map[string]string{}
+		for _, p := range pathParams {
+			values[p.Key] = p.Value
+		}
+		path = pathParamRe.ReplaceAllStringFunc(path, func(match string) string {
+			if value, ok := values[match[2:]]; ok {
+				return "/" + url.PathEscape(value)
+			}
+			return match
+		})
+	}
+
+	if len(params) > 0 {
+		query = mergeQuery(query, params)
+		hasQuery = query != ""
+	}
+
+	built := prefix + path
+	if hasQuery {
+		built += "?" + query
+	}
+	if hasFragment {
+		built += "#" + fragment
+	}
+	if _, err := url.Parse(built); err != nil {
+		return "", err
+	}
+	return built, nil
+}
+
+// mergeQuery merges params into the raw query string. Params replace the
+// values of the same key in place, where the key first appears, and new
+// keys are appended in order. When a key's values are unchanged its pairs
+// are kept as they were written.
+func mergeQuery(rawQuery string, params KeyValues) string {
+	var keys []string
+	rows := map[string][]string{}
+	for _, p := range params {
+		if _, ok := rows[p.Key]; !ok {
+			keys = append(keys, p.Key)
+		}
+		rows[p.Key] = append(rows[p.Key], p.Value)
+	}
+
+	type pair struct{ raw, key, value string }
+	var pairs []pair
+	existing := map[string][]string{}
+	if rawQuery != "" {
+		for _, raw := range strings.Split(rawQuery, "&") {
+			key, value, _ := strings.Cut(raw, "=")
+			if k, err := url.QueryUnescape(key); err == nil {
+				key = k
+			}
+			if v, err := url.QueryUnescape(value); err == nil {
+				value = v
+			}
+			pairs = append(pairs, pair{raw, key, value})
+			existing[key] = append(existing[key], value)
+		}
+	}
+
+	encode := func(key string) []string {
+		var out []string
+		for _, v := range rows[key] {
+			out = append(out, url.QueryEscape(key)+"="+url.QueryEscape(v))
+		}
+		return out
+	}
+
+	var out []string
+	done := map[string]bool{}
+	for _, p := range pairs {
+		values, ok := rows[p.key]
+		switch {
+		case !ok || slices.Equal(values, existing[p.key]):
+			out = append(out, p.raw)
+		case !done[p.key]:
+			out = append(out, encode(p.key)...)
+		}
+		done[p.key] = true
+	}
+	for _, key := range keys {
+		if !done[key] {
+			out = append(out, encode(key)...)
+		}
+	}
+	return strings.Join(out, "&")
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// PreviewURL returns the URL ExecuteRequest would send for r, with its
+// placeholders, path params and query params applied. An API key sent in
+// the query is added later and not shown.
+func (a *App) PreviewURL(r Request) (string, error) {
+	scopes, err := a.variableScopes(getSavedData(), r, nil)
+	if err != nil {
+		return "", err
+	}
+	variables := scopes.merged()
+	return buildURL(replacePlaceholders(r.URL, variables), r.QueryParams.resolved(variables), r.PathParams.resolved(variables))
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"example.com/a", "https://example.com/a"},
+		{"http://example.com/a?x=1", "http://example.com/a?x=1"},
+		{"example.com/cb?next=https://x", "https://example.com/cb?next=https://x"},
+		{"example.com#frag://x", "https://example.com#frag://x"},
+		{"/?next=http://x", "https:///?next=http://x"},
+		{"?r=https://a", "https://?r=https://a"},
+		{"a?u=http://b", "https://a?u=http://b"},
+	}
+	for _, tt := range tests {
+		got, err := buildURL(tt.in, nil, nil)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Variable scope names, reported by ResolveVariables.
+const (
+	// ScopeLocal holds the variables scripts set with pm.variables; they
+	// last for one send, or one collection run
+	ScopeLocal   = "local"
+	ScopeRequest = "request"
+	// ScopeData holds the current row of a runner data file
+	ScopeData        = "data"
+	ScopeEnvironment = "environment"
+	ScopeCollection  = "collection"
+	ScopeGlobal      = "global"
+)
+
+// variableScope is one layer of variables, e.g. the active environment.
+type variableScope struct {
+	name string
+	vars map[string]string
+}
+
+// variableScopes is ordered from highest to lowest precedence: the first
+// scope defining a key wins.
+type variableScopes []variableScope
+
+// ResolvedVariable describes how one {{placeholder}} in a request resolved.
+type ResolvedVariable struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Scope    string `json:"scope"`
+	Resolved bool   `json:"resolved"`
+}
+
+// parseScopeVariables decodes a JSON object of variables as stored in
+// SavedData. An empty string is treated as no variables.
+func parseScopeVariables(scope, variablesJSON string) (variableScope, error) {
+	s := variableScope{name: scope, vars: map[string]string{}}
+	if strings.TrimSpace(variablesJSON) == "" {
+		return s, nil
+	}
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(variablesJSON), &raw); err != nil {
+		return s, fmt.Errorf("Error parsing %s variables", scope)
+	}
+	s.vars = coerceVariables(raw)
+	return s, nil
+}
+
+// variableScopes builds the scopes used to send r, in precedence order:
+// request overrides, the data file row of a collection run (if row is not
+// nil), active environment, the request's folder and its ancestors
+// (collection, nearest first), then globals (the top-level
+// SavedData.Variables).
+func (a *App) variableScopes(data SavedData, r Request, row map[string]string) (variableScopes, error) {
+	var scopes variableScopes
+	add := func(scope, variablesJSON string) error {
+		s, err := parseScopeVariables(scope, variablesJSON)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, s)
+		return nil
+	}
+
+	if err := add(ScopeRequest, r.Variables); err != nil {
+		return nil, err
+	}
+	if row != nil {
+		scopes = append(scopes, variableScope{name: ScopeData, vars: row})
+	}
+	envVars := ""
+	if env := a.environmentFor(&data); env != nil {
+		envVars = env.Variables
+	}
+	if err := add(ScopeEnvironment, envVars); err != nil {
+		return nil, err
+	}
+	for _, folder := range data.folderChain(r.FolderId) {
+		if err := add(ScopeCollection, folder.Variables); err != nil {
+			return nil, err
+		}
+	}
+	if err := add(ScopeGlobal, data.Variables); err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
+
+// environmentFor returns the environment requests are resolved against: the
+// App's environment override if set (used by the CLI), else the persisted
+// active environment.
+func (a *App) environmentFor(data *SavedData) *Environment {
+	if a.environmentId != "" {
+		return data.environment(a.environmentId)
+	}
+	return data.activeEnvironment()
+}
+
+// lookup returns the value of key and the name of the scope that defined it.
+func (s variableScopes) lookup(key string) (value, scope string, ok bool) {
+	for _, sc := range s {
+		if v, exists := sc.vars[key]; exists {
+			return v, sc.name, true
+		}
+	}
+	return "", "", false
+}
+
+// merged flattens the scopes into a single map honoring precedence.
+func (s variableScopes) merged() map[string]string {
+	out := map[string]string{}
+	for i := len(s) - 1; i >= 0; i-- {
+		for k, v := range s[i].vars {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// --- Exported Methods (Callable from JS) ---
+
+// GetGlobals returns the global variables, which apply to every request
+// regardless of the active environment.
+func (a *App) GetGlobals() string {
+	vars := getSavedData().Variables
+	if vars == "" {
+		return "{}"
+	}
+	return vars
+}
+
+func (a *App) SaveGlobals(variableString string) error {
+	coerced, err := normalizeVariables(variableString)
+	if err != nil {
+		return err
+	}
+	return a.mutateSavedData(func(data *SavedData) {
+		data.Variables = coerced
+	})
+}
+
+// ResolveVariables reports, for every distinct {{placeholder}} in r's URL,
+// enabled headers, query params and form fields, body, in the headers it
+// inherits from its folders and in the auth it sends with, the value it resolves to
+// and the scope it came from, in order of first appearance. Unresolved
+// placeholders are included with Resolved false.
+func (a *App) ResolveVariables(r Request) ([]ResolvedVariable, error) {
+	data := getSavedData()
+	scopes, err := a.variableScopes(data, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	variables := scopes.merged()
+
+	seen := map[string]bool{}
+	var result []ResolvedVariable
+	fields := []string{r.URL}
+	inherited := inheritedHeaderRows(data.folderChain(r.FolderId), r.Headers, variables)
+	// Disabled rows and form fields are not sent
+	for _, rows := range []KeyValues{r.Headers, r.QueryParams, r.PathParams, inherited} {
+		for _, kv := range rows {
+			if kv.Enabled {
+				fields = append(fields, kv.Key, kv.Value)
+			}
+		}
+	}
+	fields = append(fields, r.Body, r.BodyFile, r.GraphQLVariables)
+	for _, f := range r.Form {
+		if f.Enabled {
+			fields = append(fields, f.Key, f.Value)
+		}
+	}
+	if auth := effectiveAuth(&data, r); auth != nil {
+		for _, field := range auth.templateFields() {
+			fields = append(fields, *field)
+		}
+	}
+	for _, field := range fields {
+		for _, sub := range placeholderRe.FindAllStringSubmatch(field, -1) {
+			key := strings.TrimSpace(sub[1])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			value, scope, ok := scopes.lookup(key)
+			result = append(result, ResolvedVariable{Name: key, Value: value, Scope: scope, Resolved: ok})
+		}
+	}
+	return result, nil
+}
+
+// inheritedHeaderRows returns the unresolved folder header rows that send
+// uses alongside the request's headers: the enabled rows of each folder in
+// chain whose name, once resolved, is not set by the request or by a nearer
+// folder (see folderHeaders).
+func inheritedHeaderRows(chain []Folder, headers KeyValues, variables map[string]string) KeyValues {
+	overridden := headers.resolved(variables)
+	var rows KeyValues
+	for _, folder := range chain {
+		resolved := folder.Headers.resolved(variables)
+		for _, kv := range folder.Headers {
+			name := strings.TrimSpace(replacePlaceholders(kv.Key, variables))
+			if kv.Enabled && name != "" && !overridden.has(name) {
+				rows = append(rows, kv)
+			}
+		}
+		overridden = append(overridden, resolved...)
+	}
+	return rows
+}